@@ -0,0 +1,82 @@
+// benchmarks 日志性能基准。
+// 每个场景为一个BenchmarkXxx，其下按实现运行子基准，对比本库与zap、zerolog、log/slog（均输出到io.Discard或带缓冲的文件）；
+// 各实现的用例bench<场景><实现>位于对应库的文件中。
+// 独立的go.mod使对比用的依赖不进入本库。
+//
+// 运行：
+//
+//	cd benchmarks && go test -run ^$ -bench . [-bench Fields10] [-count 10] [-cpu 1,4,8]
+//
+// 热路径的内存分配预算由本库的TestAllocBudgets检查。
+package benchmarks
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/fexli/logger"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetConsoleOutput(io.Discard)
+	code := m.Run()
+	if benchDir != "" {
+		_ = os.RemoveAll(benchDir)
+	}
+	os.Exit(code)
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	b.Run("Fexli", benchDisabledFexli)
+	b.Run("FexliEnabledGuard", benchDisabledFexliEnabledGuard)
+	b.Run("FexliLazyContent", benchDisabledFexliLazyContent)
+	b.Run("FexliLogWithLevel", benchDisabledFexliLogWithLevel)
+	b.Run("FexliWithContent", benchDisabledFexliWithContent)
+	b.Run("Slog", benchDisabledSlog)
+	b.Run("Zap", benchDisabledZap)
+	b.Run("Zerolog", benchDisabledZerolog)
+}
+
+func BenchmarkSimple(b *testing.B) {
+	b.Run("Fexli", benchSimpleFexli)
+	b.Run("FexliColored", benchSimpleFexliColored)
+	b.Run("FexliJSON", benchSimpleFexliJSON)
+	b.Run("FexliSlogHandler", benchSimpleFexliSlogHandler)
+	b.Run("Slog", benchSimpleSlog)
+	b.Run("Zap", benchSimpleZap)
+	b.Run("Zerolog", benchSimpleZerolog)
+}
+
+func BenchmarkFields10(b *testing.B) {
+	b.Run("Fexli", benchFields10Fexli)
+	b.Run("FexliJSON", benchFields10FexliJSON)
+	b.Run("FexliTyped", benchFields10FexliTyped)
+	b.Run("Slog", benchFields10Slog)
+	b.Run("SlogKVs", benchFields10SlogKVs)
+	b.Run("Zap", benchFields10Zap)
+	b.Run("ZapSugar", benchFields10ZapSugar)
+	b.Run("Zerolog", benchFields10Zerolog)
+	b.Run("ZerologFields", benchFields10ZerologFields)
+}
+
+func BenchmarkStruct(b *testing.B) {
+	b.Run("Fexli", benchStructFexli)
+	b.Run("Slog", benchStructSlog)
+	b.Run("Zap", benchStructZap)
+	b.Run("Zerolog", benchStructZerolog)
+}
+
+func BenchmarkFile(b *testing.B) {
+	b.Run("FexliJSON", benchFileFexliJSON)
+	b.Run("Slog", benchFileSlog)
+	b.Run("Zap", benchFileZap)
+	b.Run("Zerolog", benchFileZerolog)
+}
+
+func BenchmarkParallel(b *testing.B) {
+	b.Run("Fexli", benchParallelFexli)
+	b.Run("Slog", benchParallelSlog)
+	b.Run("Zap", benchParallelZap)
+	b.Run("Zerolog", benchParallelZerolog)
+}
@@ -0,0 +1,62 @@
+package benchmarks
+
+import (
+	"testing"
+
+	"github.com/fexli/logger"
+)
+
+// disabledLogger 返回只输出Warning及以上等级的Logger，Debug等级处于关闭状态
+func disabledLogger() *logger.Logger {
+	l := logger.GetLogger("bench.disabled", true)
+	l.SetLogLevel(logger.LevelAtLeast(logger.LevelWarning))
+	return l
+}
+
+func benchDisabledFexli(b *testing.B) {
+	b.ReportAllocs()
+	l := disabledLogger()
+	content := logger.WithContent(benchMessage)
+	for i := 0; i < b.N; i++ {
+		l.Debug(content)
+	}
+}
+
+func benchDisabledFexliWithContent(b *testing.B) {
+	b.ReportAllocs()
+	l := disabledLogger()
+	for i := 0; i < b.N; i++ {
+		l.Debug(logger.WithContent(benchMessage, i))
+	}
+}
+
+func benchDisabledFexliLazyContent(b *testing.B) {
+	b.ReportAllocs()
+	l := disabledLogger()
+	content := logger.WithLazyContent(func() []logger.LogCtx {
+		return []logger.LogCtx{benchMessage, 200}
+	})
+	for i := 0; i < b.N; i++ {
+		l.Debug(content)
+	}
+}
+
+func benchDisabledFexliEnabledGuard(b *testing.B) {
+	b.ReportAllocs()
+	l := disabledLogger()
+	for i := 0; i < b.N; i++ {
+		if l.Enabled(logger.LevelDebug) {
+			l.Debug(logger.WithContent(benchMessage, i))
+		}
+	}
+}
+
+func benchDisabledFexliLogWithLevel(b *testing.B) {
+	b.ReportAllocs()
+	l := disabledLogger()
+	level := logger.WithLevel(logger.LevelDebug)
+	content := logger.WithContent(benchMessage)
+	for i := 0; i < b.N; i++ {
+		l.Log(level, content)
+	}
+}
@@ -0,0 +1,104 @@
+package benchmarks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fexli/logger"
+	"github.com/xo/terminfo"
+)
+
+func benchSimpleFexli(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.simple")
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage))
+	}
+}
+
+func benchSimpleFexliJSON(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.simple.json").SetFormatter(logger.JSONFormatter{})
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage))
+	}
+}
+
+func benchSimpleFexliColored(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.simple.colored")
+	logger.ForceSetColor(terminfo.ColorLevelMillions)
+	defer logger.DisableColor()
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage))
+	}
+}
+
+func benchFields10Fexli(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.fields")
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage), logger.WithKVs(tenKVs...))
+	}
+}
+
+func benchFields10FexliTyped(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.fields.typed")
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage), logger.WithFields(
+			logger.String("method", "GET"),
+			logger.String("path", "/api/v1/users"),
+			logger.Int("status", 200),
+			logger.Int("bytes", 5120),
+			logger.Duration("latency", 3*time.Millisecond),
+			logger.String("ip", "10.0.0.1"),
+			logger.Int("user", 1001),
+			logger.Bool("cached", true),
+			logger.Float64("ratio", 0.75),
+			logger.Err(benchErr),
+		))
+	}
+}
+
+func benchFields10FexliJSON(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.fields.json").SetFormatter(logger.JSONFormatter{})
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage), logger.WithKVs(tenKVs...))
+	}
+}
+
+func benchStructFexli(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.struct")
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage), logger.WithStruct(benchStruct))
+	}
+}
+
+func benchFileFexliJSON(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.file")
+	// 只写入文件，不输出到控制台
+	l.DefaultIO = nil
+	sink, err := logger.NewFileSink(logger.Config{Name: benchFile("fexli.log"), ArchiveDir: benchFile("logs"), Durability: logger.DurabilityBuffered}, logger.LevelDefault)
+	if err != nil {
+		b.Fatal(err)
+	}
+	sink.SetFormatter(logger.JSONFormatter{}).Attach(l)
+	defer sink.Close()
+	for i := 0; i < b.N; i++ {
+		l.Common(logger.WithContent(benchMessage), logger.WithKVs("status", 200, "user", 1001))
+	}
+}
+
+func benchParallelFexli(b *testing.B) {
+	b.ReportAllocs()
+	l := newFexli("bench.parallel")
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Common(logger.WithContent(benchMessage), logger.WithKVs("status", 200, "user", 1001))
+		}
+	})
+}
@@ -0,0 +1,61 @@
+package benchmarks
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fexli/logger"
+)
+
+// benchMessage 各用例使用的日志消息
+const benchMessage = "request finished"
+
+// benchUser WithStruct及各库结构体字段用例使用的结构体
+type benchUser struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Admin     bool      `json:"admin"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+var (
+	benchStruct = benchUser{ID: 1001, Name: "fexli", Email: "fexli@example.com", Admin: true, CreatedAt: time.Unix(1660287551, 0)}
+	benchErr    = errors.New("connection reset by peer")
+)
+
+// tenKVs WithKVs用例的10个字段，其余库使用等价的强类型字段
+var tenKVs = []interface{}{
+	"method", "GET",
+	"path", "/api/v1/users",
+	"status", 200,
+	"bytes", 5120,
+	"latency", 3 * time.Millisecond,
+	"ip", "10.0.0.1",
+	"user", 1001,
+	"cached", true,
+	"ratio", 0.75,
+	"error", benchErr,
+}
+
+// benchDir 文件用例的临时目录，由TestMain在退出前删除
+var benchDir string
+
+// benchFile 返回benchDir中名为name的文件路径，首次调用时创建benchDir
+func benchFile(name string) string {
+	if benchDir == "" {
+		dir, err := os.MkdirTemp("", "logger-bench")
+		if err != nil {
+			panic(err)
+		}
+		benchDir = dir
+	}
+	return filepath.Join(benchDir, name)
+}
+
+// newFexli 返回名为name的Logger，历史记录保留最近1000条，避免基准过程中无限增长
+func newFexli(name string) *logger.Logger {
+	return logger.GetLogger(name, false).SetRetention(logger.Retention{MaxEntries: 1000})
+}
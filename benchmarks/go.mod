@@ -0,0 +1,22 @@
+module github.com/fexli/logger/benchmarks
+
+go 1.21
+
+require (
+	github.com/fexli/logger v0.0.0
+	github.com/rs/zerolog v1.33.0
+	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778
+	go.uber.org/zap v1.27.0
+)
+
+require (
+	github.com/ahmetb/go-linq/v3 v3.2.0 // indirect
+	github.com/fatih/structs v1.1.0 // indirect
+	github.com/mattn/go-colorable v0.1.13 // indirect
+	github.com/mattn/go-isatty v0.0.19 // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	golang.org/x/sys v0.12.0 // indirect
+)
+
+replace github.com/fexli/logger => ../
@@ -0,0 +1,106 @@
+package benchmarks
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/fexli/logger"
+)
+
+// newSlog 返回以JSON格式写入w的slog.Logger
+func newSlog(w io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(w, &slog.HandlerOptions{Level: level}))
+}
+
+func slogAttrs10() []slog.Attr {
+	return []slog.Attr{
+		slog.String("method", "GET"),
+		slog.String("path", "/api/v1/users"),
+		slog.Int("status", 200),
+		slog.Int("bytes", 5120),
+		slog.Duration("latency", 3*time.Millisecond),
+		slog.String("ip", "10.0.0.1"),
+		slog.Int("user", 1001),
+		slog.Bool("cached", true),
+		slog.Float64("ratio", 0.75),
+		slog.Any("error", benchErr),
+	}
+}
+
+func benchDisabledSlog(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelWarn)
+	for i := 0; i < b.N; i++ {
+		l.Debug(benchMessage)
+	}
+}
+
+func benchSimpleSlog(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelDebug)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage)
+	}
+}
+
+func benchSimpleFexliSlogHandler(b *testing.B) {
+	b.ReportAllocs()
+	l := slog.New(logger.NewSlogHandler(newFexli("bench.slog")))
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage)
+	}
+}
+
+func benchFields10Slog(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelDebug)
+	for i := 0; i < b.N; i++ {
+		l.LogAttrs(context.Background(), slog.LevelInfo, benchMessage, slogAttrs10()...)
+	}
+}
+
+func benchFields10SlogKVs(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelDebug)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, tenKVs...)
+	}
+}
+
+func benchStructSlog(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelDebug)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, slog.Any("user", benchStruct))
+	}
+}
+
+func benchFileSlog(b *testing.B) {
+	b.ReportAllocs()
+	f, err := os.Create(benchFile("slog.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, 64<<10)
+	defer w.Flush()
+	l := newSlog(w, slog.LevelDebug)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, slog.Int("status", 200), slog.Int("user", 1001))
+	}
+}
+
+func benchParallelSlog(b *testing.B) {
+	b.ReportAllocs()
+	l := newSlog(io.Discard, slog.LevelDebug)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info(benchMessage, slog.Int("status", 200), slog.Int("user", 1001))
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package benchmarks
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// zapDiscard 丢弃输出的zapcore.WriteSyncer
+var zapDiscard = zapcore.AddSync(io.Discard)
+
+// newZap 返回以JSON格式写入w的zap.Logger
+func newZap(w zapcore.WriteSyncer, level zapcore.Level) *zap.Logger {
+	enc := zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig())
+	return zap.New(zapcore.NewCore(enc, w, level))
+}
+
+func zapFields10() []zap.Field {
+	return []zap.Field{
+		zap.String("method", "GET"),
+		zap.String("path", "/api/v1/users"),
+		zap.Int("status", 200),
+		zap.Int("bytes", 5120),
+		zap.Duration("latency", 3*time.Millisecond),
+		zap.String("ip", "10.0.0.1"),
+		zap.Int("user", 1001),
+		zap.Bool("cached", true),
+		zap.Float64("ratio", 0.75),
+		zap.Error(benchErr),
+	}
+}
+
+func benchDisabledZap(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.WarnLevel)
+	for i := 0; i < b.N; i++ {
+		l.Debug(benchMessage)
+	}
+}
+
+func benchSimpleZap(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage)
+	}
+}
+
+func benchFields10Zap(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, zapFields10()...)
+	}
+}
+
+func benchFields10ZapSugar(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.DebugLevel).Sugar()
+	for i := 0; i < b.N; i++ {
+		l.Infow(benchMessage, tenKVs...)
+	}
+}
+
+func benchStructZap(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, zap.Any("user", benchStruct))
+	}
+}
+
+func benchFileZap(b *testing.B) {
+	b.ReportAllocs()
+	f, err := os.Create(benchFile("zap.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	ws := &zapcore.BufferedWriteSyncer{WS: zapcore.AddSync(f), Size: 64 << 10}
+	defer ws.Stop()
+	l := newZap(ws, zapcore.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info(benchMessage, zap.Int("status", 200), zap.Int("user", 1001))
+	}
+}
+
+func benchParallelZap(b *testing.B) {
+	b.ReportAllocs()
+	l := newZap(zapDiscard, zapcore.DebugLevel)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info(benchMessage, zap.Int("status", 200), zap.Int("user", 1001))
+		}
+	})
+}
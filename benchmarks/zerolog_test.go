@@ -0,0 +1,92 @@
+package benchmarks
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// newZerolog 返回写入w的带时间戳的zerolog.Logger
+func newZerolog(w io.Writer, level zerolog.Level) zerolog.Logger {
+	return zerolog.New(w).Level(level).With().Timestamp().Logger()
+}
+
+func benchDisabledZerolog(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.WarnLevel)
+	for i := 0; i < b.N; i++ {
+		l.Debug().Msg(benchMessage)
+	}
+}
+
+func benchSimpleZerolog(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info().Msg(benchMessage)
+	}
+}
+
+func benchFields10Zerolog(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info().
+			Str("method", "GET").
+			Str("path", "/api/v1/users").
+			Int("status", 200).
+			Int("bytes", 5120).
+			Dur("latency", 3*time.Millisecond).
+			Str("ip", "10.0.0.1").
+			Int("user", 1001).
+			Bool("cached", true).
+			Float64("ratio", 0.75).
+			Err(benchErr).
+			Msg(benchMessage)
+	}
+}
+
+func benchFields10ZerologFields(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info().Fields(tenKVs).Msg(benchMessage)
+	}
+}
+
+func benchStructZerolog(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info().Interface("user", benchStruct).Msg(benchMessage)
+	}
+}
+
+func benchFileZerolog(b *testing.B) {
+	b.ReportAllocs()
+	f, err := os.Create(benchFile("zerolog.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+	w := bufio.NewWriterSize(f, 64<<10)
+	defer w.Flush()
+	l := newZerolog(w, zerolog.DebugLevel)
+	for i := 0; i < b.N; i++ {
+		l.Info().Int("status", 200).Int("user", 1001).Msg(benchMessage)
+	}
+}
+
+func benchParallelZerolog(b *testing.B) {
+	b.ReportAllocs()
+	l := newZerolog(io.Discard, zerolog.DebugLevel)
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info().Int("status", 200).Int("user", 1001).Msg(benchMessage)
+		}
+	})
+}
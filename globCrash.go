@@ -0,0 +1,14 @@
+//go:build go1.23
+// +build go1.23
+
+package logger
+
+import (
+	"os"
+	"runtime/debug"
+)
+
+// setCrashOutput 使运行时在panic或致命错误导致进程退出时，除标准错误外同时将错误信息写入f，f为nil时取消
+func setCrashOutput(f *os.File) error {
+	return debug.SetCrashOutput(f, debug.CrashOptions{})
+}
@@ -0,0 +1,11 @@
+//go:build !go1.23
+// +build !go1.23
+
+package logger
+
+import "os"
+
+// Go 1.23以前无法单独指定崩溃信息的输出，接管标准错误时panic与运行时致命错误的信息写入管道后随进程退出而丢失
+func setCrashOutput(f *os.File) error {
+	return nil
+}
@@ -31,8 +31,23 @@
 
 package logger
 
-import "runtime"
+import (
+	"os"
+	"runtime"
+)
 
 func initErr() {
 	println("SetStdOutHandle failed: " + runtime.GOARCH + " architecture with " + runtime.GOOS + " system is not supported")
 }
+
+func redirectStderr(_ *os.File) error {
+	return errStderrUnsupported
+}
+
+func dupStderr() (*os.File, error) {
+	return nil, errStderrUnsupported
+}
+
+func restoreStderr(_ *os.File) error {
+	return errStderrUnsupported
+}
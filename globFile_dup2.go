@@ -12,8 +12,29 @@ func initErr() {
 	if GlobalFileHandler == nil {
 		return
 	}
-	if err := syscall.Dup2(int(GlobalFileHandler.Fd()), int(os.Stderr.Fd())); err != nil {
+	if err := redirectStderr(GlobalFileHandler); err != nil {
 		println("SetStdOutHandle[2] failed:", err.Error())
-		os.Exit(0xD00)
+		exitHoldingGlob(0xD00)
 	}
 }
+
+// redirectStderr 将标准错误重定向到f
+func redirectStderr(f *os.File) error {
+	return syscall.Dup2(int(f.Fd()), int(os.Stderr.Fd()))
+}
+
+// dupStderr 复制当前的标准错误，用于之后通过restoreStderr恢复
+func dupStderr() (*os.File, error) {
+	fd, err := syscall.Dup(int(os.Stderr.Fd()))
+	if err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "/dev/stderr"), nil
+}
+
+// restoreStderr 将标准错误恢复为dupStderr保存的副本并关闭副本
+func restoreStderr(saved *os.File) error {
+	err := redirectStderr(saved)
+	_ = saved.Close()
+	return err
+}
@@ -14,12 +14,42 @@ func initErr() {
 	if GlobalFileHandler == nil {
 		return
 	}
+	if err := redirectStderr(GlobalFileHandler); err != nil {
+		println("SetStdOutHandle failed:", err.Error())
+		exitHoldingGlob(0xD00)
+	}
+}
+
+// redirectStderr 将标准错误重定向到f
+func redirectStderr(f *os.File) error {
 	kernal := syscall.NewLazyDLL(kernel32)
 	setStdHandle := kernal.NewProc("SetStdHandle")
 	sh := syscall.STD_ERROR_HANDLE
-	if v, _, err := setStdHandle.Call(uintptr(sh), uintptr(GlobalFileHandler.Fd())); v == 0 {
-		println("SetStdOutHandle failed:", err.Error())
-		os.Exit(0xD00)
+	defer syscall.FreeLibrary(syscall.Handle(kernal.Handle()))
+	if v, _, err := setStdHandle.Call(uintptr(sh), f.Fd()); v == 0 {
+		return err
+	}
+	return nil
+}
+
+// dupStderr 复制当前的标准错误句柄，用于之后通过restoreStderr恢复
+func dupStderr() (*os.File, error) {
+	h, err := syscall.GetStdHandle(syscall.STD_ERROR_HANDLE)
+	if err != nil {
+		return nil, err
+	}
+	proc, err := syscall.GetCurrentProcess()
+	if err != nil {
+		return nil, err
 	}
-	_ = syscall.FreeLibrary(syscall.Handle(kernal.Handle()))
+	var dup syscall.Handle
+	if err = syscall.DuplicateHandle(proc, h, proc, &dup, 0, true, syscall.DUPLICATE_SAME_ACCESS); err != nil {
+		return nil, err
+	}
+	return os.NewFile(uintptr(dup), "stderr"), nil
+}
+
+// restoreStderr 将标准错误恢复为dupStderr保存的句柄，该句柄此后作为标准错误使用，不会被关闭
+func restoreStderr(saved *os.File) error {
+	return redirectStderr(saved)
 }
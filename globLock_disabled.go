@@ -0,0 +1,11 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !windows
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!windows
+
+package logger
+
+import "os"
+
+// lockFile 当前平台不支持文件锁，返回errLockUnsupported
+func lockFile(_ *os.File, _ bool) error {
+	return errLockUnsupported
+}
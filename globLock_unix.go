@@ -0,0 +1,29 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile 对f加排他建议锁（flock），wait为false且锁已被其他进程持有时返回ErrGlobLogLocked
+func lockFile(f *os.File, wait bool) error {
+	how := syscall.LOCK_EX
+	if !wait {
+		how |= syscall.LOCK_NB
+	}
+	for {
+		err := syscall.Flock(int(f.Fd()), how)
+		switch err {
+		case nil:
+			return nil
+		case syscall.EINTR:
+			continue
+		case syscall.EWOULDBLOCK:
+			return ErrGlobLogLocked
+		}
+		return err
+	}
+}
@@ -0,0 +1,23 @@
+//go:build windows
+// +build windows
+
+package logger
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// lockFile 对f加排他锁（LockFileEx），wait为false且锁已被其他进程持有时返回ErrGlobLogLocked
+func lockFile(f *os.File, wait bool) error {
+	flags := uint32(windows.LOCKFILE_EXCLUSIVE_LOCK)
+	if !wait {
+		flags |= windows.LOCKFILE_FAIL_IMMEDIATELY
+	}
+	err := windows.LockFileEx(windows.Handle(f.Fd()), flags, 0, 1, 0, &windows.Overlapped{})
+	if err == windows.ERROR_LOCK_VIOLATION {
+		return ErrGlobLogLocked
+	}
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build !js
+// +build !js
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+var defaultReopenSignal os.Signal = syscall.SIGHUP
@@ -0,0 +1,9 @@
+//go:build js
+// +build js
+
+package logger
+
+import "os"
+
+// js平台不支持SIGHUP，需要通过Config.ReopenSignal指定信号
+var defaultReopenSignal os.Signal
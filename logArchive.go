@@ -0,0 +1,305 @@
+package logger
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Compressor 归档日志压缩方式，切片后在后台将归档文件压缩为"原文件名+Extension()"并删除原文件
+//
+// e.g. 使用zstd压缩（github.com/klauspost/compress/zstd）
+//
+//	type zstdCompressor struct{}
+//
+//	func (zstdCompressor) Extension() string { return ".zst" }
+//	func (zstdCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) { return zstd.NewWriter(w) }
+type Compressor interface {
+	// 压缩文件扩展名（包含"."）
+	Extension() string
+	// 返回将压缩结果写入w的Writer，Close时需写入全部剩余数据
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCompressor 以gzip压缩归档日志，Level为0时使用gzip.DefaultCompression
+type GzipCompressor struct {
+	Level int
+}
+
+func (GzipCompressor) Extension() string {
+	return ".gz"
+}
+
+func (c GzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	if c.Level == 0 {
+		return gzip.NewWriterLevel(w, gzip.DefaultCompression)
+	}
+	return gzip.NewWriterLevel(w, c.Level)
+}
+
+// archiveMu 串行化归档文件的压缩与清理
+var archiveMu sync.Mutex
+
+// maintainArchive 压缩刚切片的归档文件archived（为空时不压缩），并按保留策略清理ArchiveDir中的归档，在后台goroutine中执行
+func maintainArchive(current Config, archived string) {
+	archiveMu.Lock()
+	defer archiveMu.Unlock()
+	if archived != "" && current.Compress != nil {
+		_ = compressArchive(current.Compress, archived)
+	}
+	pruneArchives(current)
+}
+
+// compressArchive 将path压缩为path+Extension()，成功后删除原文件，失败时保留原文件并删除不完整的压缩文件
+func compressArchive(c Compressor, path string) (err error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	target := path + c.Extension()
+	tmp := target + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0764)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = os.Remove(tmp)
+		}
+	}()
+	w, err := c.NewWriter(dst)
+	if err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		_ = w.Close()
+		_ = dst.Close()
+		return err
+	}
+	if err = w.Close(); err != nil {
+		_ = dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, target); err != nil {
+		return err
+	}
+	_ = src.Close()
+	return os.Remove(path)
+}
+
+// archiveFile ArchiveDir中的一个归档文件
+type archiveFile struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// listArchives 按修改时间从旧到新列出ArchiveDir中的归档文件（以.log结尾或为其压缩文件），当前日志文件不计入
+func listArchives(current Config) []archiveFile {
+	entries, err := os.ReadDir(current.ArchiveDir)
+	if err != nil {
+		return nil
+	}
+	self, _ := filepath.Abs(current.Name)
+	var files []archiveFile
+	for _, entry := range entries {
+		if entry.IsDir() || !isArchiveName(current, entry.Name()) {
+			continue
+		}
+		p := filepath.Join(current.ArchiveDir, entry.Name())
+		if abs, _ := filepath.Abs(p); abs == self {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		files = append(files, archiveFile{path: p, size: info.Size(), modTime: info.ModTime()})
+	}
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].modTime.Before(files[j].modTime)
+	})
+	return files
+}
+
+// legacyArchiveRe 早期版本的默认归档文件名"2006-01-02-15-04.XX.log"（XX为2位随机字符）
+var legacyArchiveRe = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}-\d{2}-\d{2}\.[0-9A-Za-z]{2}\.log(\..+)?$`)
+
+// isArchiveName 判断文件名是否为归档日志：使用归档文件名模板时按模板匹配（默认模板同时匹配早期版本的归档文件名），
+// 自定义OldLogPath时匹配以.log结尾的文件及其压缩文件
+func isArchiveName(current Config, name string) bool {
+	if current.archiveName != nil {
+		if current.archiveName.tmpl == DefaultArchiveName && matchArchive(legacyArchiveRe, name, current.Compress) {
+			return true
+		}
+		return current.archiveName.match(name, current.Compress)
+	}
+	if strings.HasSuffix(name, ".log") {
+		return true
+	}
+	return current.Compress != nil && strings.HasSuffix(name, ".log"+current.Compress.Extension())
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Archive Name Template
+
+// DefaultArchiveName 默认的归档文件名模板
+const DefaultArchiveName = "{name}.{date:2006-01-02-15-04}.{seq}.log"
+
+var archiveTokenRe = regexp.MustCompile(`\{(name|seq|pid|host|date(?::[^}]*)?)\}`)
+
+// archiveTemplate 解析后的归档文件名模板
+type archiveTemplate struct {
+	dir  string
+	tmpl string
+	stem string
+	host string
+	re   *regexp.Regexp
+}
+
+func newArchiveTemplate(dir, tmpl, logName string) *archiveTemplate {
+	host, _ := os.Hostname()
+	host = strings.NewReplacer("/", "_", "\\", "_").Replace(host)
+	t := &archiveTemplate{
+		dir:  dir,
+		tmpl: tmpl,
+		stem: strings.TrimSuffix(filepath.Base(logName), filepath.Ext(logName)),
+		host: host,
+	}
+	pattern := ""
+	last := 0
+	for _, loc := range archiveTokenRe.FindAllStringSubmatchIndex(tmpl, -1) {
+		pattern += regexp.QuoteMeta(tmpl[last:loc[0]])
+		last = loc[1]
+		switch token := tmpl[loc[2]:loc[3]]; {
+		case token == "name":
+			pattern += regexp.QuoteMeta(t.stem)
+		case token == "host":
+			pattern += regexp.QuoteMeta(t.host)
+		case token == "seq", token == "pid":
+			pattern += `\d+`
+		default:
+			pattern += `.+?`
+		}
+	}
+	pattern += regexp.QuoteMeta(tmpl[last:])
+	t.re = regexp.MustCompile("^" + pattern + "(\\..+)?$")
+	return t
+}
+
+// render 以info的修改时间渲染模板，在第一个{seq}处分为前后两部分，hasSeq表示模板中是否包含{seq}
+func (t *archiveTemplate) render(info os.FileInfo) (prefix, suffix string, hasSeq bool) {
+	parts := make([]string, 2)
+	idx := 0
+	last := 0
+	for _, loc := range archiveTokenRe.FindAllStringSubmatchIndex(t.tmpl, -1) {
+		parts[idx] += t.tmpl[last:loc[0]]
+		last = loc[1]
+		token := t.tmpl[loc[2]:loc[3]]
+		switch {
+		case token == "name":
+			parts[idx] += t.stem
+		case token == "pid":
+			parts[idx] += strconv.Itoa(os.Getpid())
+		case token == "host":
+			parts[idx] += t.host
+		case token == "seq":
+			if idx == 0 {
+				idx, hasSeq = 1, true
+			}
+		default:
+			layout := "2006-01-02"
+			if i := strings.IndexByte(token, ':'); i >= 0 {
+				layout = token[i+1:]
+			}
+			parts[idx] += info.ModTime().Format(layout)
+		}
+	}
+	parts[idx] += t.tmpl[last:]
+	return parts[0], parts[1], hasSeq
+}
+
+// path 生成归档路径：{seq}取目录中相同前后缀的已有归档（含压缩文件）的最大序号加1，
+// 模板不含{seq}且文件已存在时在扩展名前追加序号
+func (t *archiveTemplate) path(info os.FileInfo) string {
+	prefix, suffix, hasSeq := t.render(info)
+	if !hasSeq {
+		target := filepath.Join(t.dir, prefix)
+		ext := filepath.Ext(prefix)
+		for n := 1; ; n++ {
+			if _, err := os.Stat(target); os.IsNotExist(err) {
+				return target
+			}
+			target = filepath.Join(t.dir, strings.TrimSuffix(prefix, ext)+"."+strconv.Itoa(n)+ext)
+		}
+	}
+	seq := 0
+	entries, _ := os.ReadDir(t.dir)
+	for _, entry := range entries {
+		rest := strings.TrimPrefix(entry.Name(), prefix)
+		if len(rest) == len(entry.Name()) && prefix != "" {
+			continue
+		}
+		digits := 0
+		for digits < len(rest) && rest[digits] >= '0' && rest[digits] <= '9' {
+			digits++
+		}
+		if digits == 0 || !strings.HasPrefix(rest[digits:], suffix) {
+			continue
+		}
+		if n, err := strconv.Atoi(rest[:digits]); err == nil && n > seq {
+			seq = n
+		}
+	}
+	return filepath.Join(t.dir, prefix+strconv.Itoa(seq+1)+suffix)
+}
+
+// match 判断文件名是否由该模板生成（允许带有压缩扩展名）
+func (t *archiveTemplate) match(name string, c Compressor) bool {
+	return matchArchive(t.re, name, c)
+}
+
+// matchArchive 判断文件名是否与归档文件名正则re匹配，re的第一个分组为可选的压缩扩展名
+func matchArchive(re *regexp.Regexp, name string, c Compressor) bool {
+	m := re.FindStringSubmatch(name)
+	if m == nil {
+		return false
+	}
+	return m[1] == "" || (c != nil && m[1] == c.Extension())
+}
+
+// pruneArchives 按MaxArchives、MaxArchiveSize与MaxArchiveAge从最旧的归档开始删除
+func pruneArchives(current Config) {
+	if current.MaxArchives <= 0 && current.MaxArchiveSize <= 0 && current.MaxArchiveAge <= 0 {
+		return
+	}
+	files := listArchives(current)
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	deadline := time.Now().Add(-current.MaxArchiveAge)
+	for i, f := range files {
+		remain := len(files) - i
+		if (current.MaxArchives <= 0 || remain <= current.MaxArchives) &&
+			(current.MaxArchiveSize <= 0 || total <= current.MaxArchiveSize) &&
+			(current.MaxArchiveAge <= 0 || !f.modTime.Before(deadline)) {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			total -= f.size
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// failingCompressor 创建Writer失败的Compressor
+type failingCompressor struct{}
+
+func (failingCompressor) Extension() string { return ".bad" }
+
+func (failingCompressor) NewWriter(io.Writer) (io.WriteCloser, error) {
+	return nil, errors.New("no writer")
+}
+
+func TestCompressArchive(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "globlog.2024-01-01-00-00.1.log")
+	content := strings.Repeat("line\n", 1000)
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressArchive(GzipCompressor{}, p); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(p); !os.IsNotExist(err) {
+		t.Fatalf("original archive kept: %v", err)
+	}
+	f, err := os.Open(p + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := io.ReadAll(r); err != nil || string(got) != content {
+		t.Fatalf("decompressed %d bytes, err %v", len(got), err)
+	}
+
+	// 压缩失败时保留原文件且不留下不完整的压缩文件
+	q := filepath.Join(dir, "globlog.2024-01-01-00-00.2.log")
+	if err := os.WriteFile(q, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := compressArchive(failingCompressor{}, q); err == nil {
+		t.Fatal("failing compressor reported success")
+	}
+	entries, _ := os.ReadDir(dir)
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	if len(left) != 2 || left[0] != "globlog.2024-01-01-00-00.1.log.gz" || left[1] != "globlog.2024-01-01-00-00.2.log" {
+		t.Fatalf("remaining files = %v", left)
+	}
+}
+
+func TestPruneArchives(t *testing.T) {
+	// 归档按修改时间从旧到新为a、b、c、d，大小分别为4、3、2、1字节
+	archives := []string{"a.log", "b.log.gz", "c.log", "d.log"}
+	tests := []struct {
+		name   string
+		config Config
+		want   string
+	}{
+		{"unlimited", Config{}, "a.log,b.log.gz,c.log,d.log"},
+		{"max archives", Config{MaxArchives: 2}, "c.log,d.log"},
+		{"max size", Config{MaxArchiveSize: 6}, "b.log.gz,c.log,d.log"},
+		{"max age", Config{MaxArchiveAge: 150 * time.Minute}, "c.log,d.log"},
+		{"combined", Config{MaxArchives: 3, MaxArchiveSize: 3}, "c.log,d.log"},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for i, name := range archives {
+			p := filepath.Join(dir, name)
+			if err := os.WriteFile(p, []byte(strings.Repeat("x", len(archives)-i)), 0644); err != nil {
+				t.Fatal(err)
+			}
+			mod := time.Now().Add(-time.Duration(len(archives)-i) * time.Hour)
+			if err := os.Chtimes(p, mod, mod); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// 当前日志文件与非归档文件不会被清理
+		for _, name := range []string{"globlog.log", "notes.txt"} {
+			if err := os.WriteFile(filepath.Join(dir, name), []byte("keep"), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		current := tt.config
+		current.Name = filepath.Join(dir, "globlog.log")
+		current.ArchiveDir = dir
+		current.Compress = GzipCompressor{}
+		pruneArchives(current)
+
+		entries, _ := os.ReadDir(dir)
+		var left []string
+		for _, e := range entries {
+			if name := e.Name(); name != "globlog.log" && name != "notes.txt" {
+				left = append(left, name)
+			}
+		}
+		if len(entries)-len(left) != 2 {
+			t.Errorf("%s: current log or non-archive file removed", tt.name)
+		}
+		sort.Strings(left)
+		if got := strings.Join(left, ","); got != tt.want {
+			t.Errorf("%s: remaining %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestIsArchiveName(t *testing.T) {
+	def := mergeConfig(Config{Name: "globlog.log", Compress: GzipCompressor{}})
+	custom := mergeConfig(Config{Name: "globlog.log", ArchiveName: "{name}-{seq}.log"})
+	tests := []struct {
+		config Config
+		name   string
+		want   bool
+	}{
+		{def, "globlog.2024-01-15-10-20.1.log", true},
+		{def, "globlog.2024-01-15-10-20.12.log.gz", true},
+		{def, "globlog.2024-01-15-10-20.1.log.zst", false},
+		{def, "access.2024-01-15-10-20.1.log", false},
+		// 早期版本的默认归档文件名
+		{def, "2024-01-15-10-20.aZ.log", true},
+		{def, "2024-01-15-10-20.a9.log.gz", true},
+		{def, "2024-01-15-10-20.abc.log", false},
+		{def, "notes.log", false},
+		{custom, "globlog-3.log", true},
+		{custom, "2024-01-15-10-20.aZ.log", false},
+	}
+	for _, tt := range tests {
+		if got := isArchiveName(tt.config, tt.name); got != tt.want {
+			t.Errorf("isArchiveName(%q, %q) = %v, want %v", tt.config.ArchiveName, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPruneLegacyArchives(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{"2023-01-01-00-00.ab.log", "2023-01-02-00-00.cd.log", "globlog.2024-01-01-00-00.1.log", "notes.log"}
+	for i, name := range names {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod := time.Now().Add(time.Duration(i-len(names)) * time.Hour)
+		if err := os.Chtimes(p, mod, mod); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pruneArchives(mergeConfig(Config{Name: filepath.Join(t.TempDir(), "globlog.log"), ArchiveDir: dir, MaxArchives: 1}))
+
+	entries, _ := os.ReadDir(dir)
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	sort.Strings(left)
+	if len(left) != 2 || left[0] != "globlog.2024-01-01-00-00.1.log" || left[1] != "notes.log" {
+		t.Fatalf("remaining files = %v", left)
+	}
+}
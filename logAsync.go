@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy 异步队列已满时的处理策略
+type OverflowPolicy uint8
+
+const (
+	// OverflowBlock 阻塞调用方直到队列出现空位
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropNewest 丢弃新写入的日志
+	OverflowDropNewest
+	// OverflowDropOldest 丢弃队列中最早的日志
+	OverflowDropOldest
+	// OverflowDropBelow 丢弃等级低于AsyncConfig.DropBelow的新日志，其余日志阻塞等待
+	OverflowDropBelow
+)
+
+// AsyncConfig 异步输出配置
+type AsyncConfig struct {
+	// 队列长度，默认1024
+	QueueSize int
+	// 每批最多投递的日志条数，默认64
+	BatchSize int
+	// 队列已满时的处理策略
+	Overflow OverflowPolicy
+	// 仅在Overflow为OverflowDropBelow时生效，低于该等级的日志在队列已满时被丢弃
+	DropBelow LogLevel
+}
+
+// AsyncStats 异步队列计数
+type AsyncStats struct {
+	// 成功进入队列的日志数
+	Enqueued uint64 `json:"enqueued"`
+	// 已投递给Printer与DefaultIO的日志数
+	Delivered uint64 `json:"delivered"`
+	// 因队列已满被丢弃的日志数（含入队后被OverflowDropOldest挤出的日志）
+	Dropped uint64 `json:"dropped"`
+}
+
+// asyncQueue 有界日志队列，由单个worker按批次投递
+type asyncQueue struct {
+	mu       sync.Mutex
+	notEmpty *sync.Cond
+	notFull  *sync.Cond
+	progress *sync.Cond
+
+	buf    []*LoggInfo
+	head   int
+	n      int
+	batch  []*LoggInfo
+	closed bool
+
+	cfg     AsyncConfig
+	deliver func(dump *LoggInfo)
+	done    chan struct{}
+	// worker goroutine的编号，原子访问
+	worker uint64
+
+	// 以下计数均由mu保护
+	enqueued  uint64 // 已入队的日志数
+	delivered uint64 // 已离开队列（投递或被OverflowDropOldest丢弃）的日志数
+	evicted   uint64 // 被OverflowDropOldest丢弃的日志数
+	dropped   uint64 // 被丢弃的日志总数
+}
+
+func newAsyncQueue(cfg AsyncConfig, deliver func(dump *LoggInfo)) *asyncQueue {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1024
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 64
+	}
+	q := &asyncQueue{
+		buf:     make([]*LoggInfo, cfg.QueueSize),
+		batch:   make([]*LoggInfo, 0, cfg.BatchSize),
+		cfg:     cfg,
+		deliver: deliver,
+		done:    make(chan struct{}),
+	}
+	q.notEmpty = sync.NewCond(&q.mu)
+	q.notFull = sync.NewCond(&q.mu)
+	q.progress = sync.NewCond(&q.mu)
+	go q.run()
+	return q
+}
+
+// put 将日志放入队列，队列已关闭时返回false，由调用方同步输出；
+// worker自身（Printer内再次输出日志）遇到队列已满需要阻塞时同样返回false，避免等待自己腾出空位
+func (q *asyncQueue) put(dump *LoggInfo) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for q.n == len(q.buf) && !q.closed {
+		switch {
+		case q.cfg.Overflow == OverflowDropNewest,
+			q.cfg.Overflow == OverflowDropBelow && dump.Level < q.cfg.DropBelow:
+			q.dropped++
+			return true
+		case q.cfg.Overflow == OverflowDropOldest:
+			q.buf[q.head] = nil
+			q.head = (q.head + 1) % len(q.buf)
+			q.n--
+			q.delivered++
+			q.evicted++
+			q.dropped++
+		default:
+			if q.onWorker() {
+				return false
+			}
+			q.notFull.Wait()
+		}
+	}
+	if q.closed {
+		return false
+	}
+	q.buf[(q.head+q.n)%len(q.buf)] = dump
+	q.n++
+	q.enqueued++
+	q.notEmpty.Signal()
+	return true
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	atomic.StoreUint64(&q.worker, goroutineID())
+	for {
+		q.mu.Lock()
+		for q.n == 0 && !q.closed {
+			q.notEmpty.Wait()
+		}
+		if q.n == 0 && q.closed {
+			q.mu.Unlock()
+			return
+		}
+		batch := q.batch[:0]
+		for q.n > 0 && len(batch) < q.cfg.BatchSize {
+			batch = append(batch, q.buf[q.head])
+			q.buf[q.head] = nil
+			q.head = (q.head + 1) % len(q.buf)
+			q.n--
+		}
+		q.notFull.Broadcast()
+		q.mu.Unlock()
+
+		for i, dump := range batch {
+			q.deliver(dump)
+			batch[i] = nil
+		}
+
+		q.mu.Lock()
+		q.delivered += uint64(len(batch))
+		q.progress.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// flush 等待调用前已入队的日志全部投递完成，由worker自身调用时直接返回（其正在投递的批次无法在返回前完成）
+func (q *asyncQueue) flush() {
+	if q.onWorker() {
+		return
+	}
+	q.mu.Lock()
+	target := q.enqueued
+	for q.delivered < target {
+		q.progress.Wait()
+	}
+	q.mu.Unlock()
+}
+
+// close 停止接收新日志，投递剩余日志后退出worker；由worker自身调用时不等待其退出
+func (q *asyncQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.notEmpty.Broadcast()
+	q.notFull.Broadcast()
+	q.mu.Unlock()
+	if !q.onWorker() {
+		<-q.done
+	}
+}
+
+// onWorker 判断调用方是否为该队列的worker goroutine
+func (q *asyncQueue) onWorker() bool {
+	return atomic.LoadUint64(&q.worker) == goroutineID()
+}
+
+// goroutineID 从调用栈首行"goroutine N [...]"中解析当前goroutine的编号，仅在可能阻塞的路径上使用
+func goroutineID() uint64 {
+	var buf [64]byte
+	b := bytes.TrimPrefix(buf[:runtime.Stack(buf[:], false)], []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, _ := strconv.ParseUint(string(b), 10, 64)
+	return id
+}
+
+func (q *asyncQueue) stats() AsyncStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return AsyncStats{
+		Enqueued:  q.enqueued,
+		Delivered: q.delivered - q.evicted,
+		Dropped:   q.dropped,
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Logger Async Functions
+
+// EnableAsync 为当前Logger（及共享其状态的派生Logger）开启异步输出，Printer与DefaultIO将在独立的goroutine中按批次执行，
+// 重复调用时会先投递完旧队列中的日志再启用新配置
+//
+// e.g.
+//
+//	logger.RootLogger.EnableAsync(logger.AsyncConfig{QueueSize: 4096, Overflow: logger.OverflowDropOldest})
+//	defer logger.RootLogger.Close()
+func (l *Logger) EnableAsync(cfg AsyncConfig) *Logger {
+	q := newAsyncQueue(cfg, deliverAsync)
+	l.mu.Lock()
+	old := l.async
+	l.async = q
+	l.mu.Unlock()
+	if old != nil {
+		old.close()
+	}
+	return l
+}
+
+// deliverAsync 由产生日志的Logger（可能为派生Logger）输出日志
+func deliverAsync(dump *LoggInfo) {
+	dump.from.print(dump)
+}
+
+// Flush 等待当前Logger异步队列中已有的日志全部输出，未开启异步输出时直接返回
+func (l *Logger) Flush() {
+	l.mu.RLock()
+	q := l.async
+	l.mu.RUnlock()
+	if q != nil {
+		q.flush()
+	}
+}
+
+// Close 投递完异步队列中的剩余日志并关闭异步输出，之后的日志恢复同步输出
+func (l *Logger) Close() {
+	l.mu.Lock()
+	q := l.async
+	l.async = nil
+	l.mu.Unlock()
+	if q != nil {
+		q.close()
+	}
+}
+
+// AsyncStats 获取当前Logger异步队列的计数，未开启异步输出时返回零值
+func (l *Logger) AsyncStats() AsyncStats {
+	l.mu.RLock()
+	q := l.async
+	l.mu.RUnlock()
+	if q == nil {
+		return AsyncStats{}
+	}
+	return q.stats()
+}
+
+// Flush 等待全部Logger异步队列中已有的日志输出完成，通常在程序退出前调用
+func Flush() {
+	poolMu.Lock()
+	loggers := make([]*Logger, 0, len(pool))
+	for _, l := range pool {
+		loggers = append(loggers, l)
+	}
+	poolMu.Unlock()
+	for _, l := range loggers {
+		l.Flush()
+	}
+}
@@ -0,0 +1,177 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// within 在d内等待f返回，超时视为死锁
+func within(t *testing.T, d time.Duration, f func()) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	select {
+	case <-done:
+	case <-time.After(d):
+		t.Fatal("timed out, probable deadlock")
+	}
+}
+
+func TestAsyncFatalFromPrinter(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	l.EnableAsync(AsyncConfig{})
+	defer l.Close()
+	var fatal int32
+	l.AddPrinter(func(info *LoggInfo) {
+		if info.Level == LevelCommon {
+			l.Fatal(WithContent("fatal from printer"))
+		} else if info.Level == LevelFatal {
+			atomic.AddInt32(&fatal, 1)
+		}
+	})
+	within(t, 2*time.Second, func() {
+		l.Common(WithContent("trigger"))
+		l.Flush()
+		l.Flush()
+	})
+	if atomic.LoadInt32(&fatal) != 1 {
+		t.Fatalf("fatal delivered %d times, want 1", fatal)
+	}
+}
+
+func TestAsyncBlockingQueueFromPrinter(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	l.EnableAsync(AsyncConfig{QueueSize: 1, BatchSize: 1, Overflow: OverflowBlock})
+	defer l.Close()
+	var delivered int32
+	l.AddPrinter(func(info *LoggInfo) {
+		atomic.AddInt32(&delivered, 1)
+		if info.Level == LevelCommon {
+			// 队列长度为1，worker在Printer中连续输出日志时队列必然已满
+			for i := 0; i < 3; i++ {
+				l.Warning(WithContent("from printer", i))
+			}
+		}
+	})
+	within(t, 2*time.Second, func() {
+		l.Common(WithContent("trigger"))
+		l.Flush()
+	})
+	if got := atomic.LoadInt32(&delivered); got != 4 {
+		t.Fatalf("delivered %d entries, want 4", got)
+	}
+}
+
+func TestAsyncCloseFromPrinter(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	l.EnableAsync(AsyncConfig{})
+	l.AddPrinter(func(info *LoggInfo) {
+		if info.Level == LevelCommon {
+			l.Close()
+		}
+	})
+	within(t, 2*time.Second, func() {
+		l.Common(WithContent("trigger"))
+		l.Flush()
+		l.Warning(WithContent("after close"))
+	})
+	if stats := l.AsyncStats(); stats != (AsyncStats{}) {
+		t.Fatalf("async still enabled: %+v", stats)
+	}
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	tests := []struct {
+		name          string
+		overflow      OverflowPolicy
+		wantDelivered string
+		wantStats     AsyncStats
+	}{
+		{"drop newest", OverflowDropNewest, "0,1,2", AsyncStats{Enqueued: 3, Delivered: 3, Dropped: 2}},
+		{"drop oldest", OverflowDropOldest, "0,3,4", AsyncStats{Enqueued: 5, Delivered: 3, Dropped: 2}},
+		{"block", OverflowBlock, "0,1,2,3,4", AsyncStats{Enqueued: 5, Delivered: 5}},
+	}
+	for _, tt := range tests {
+		var delivered []string
+		started := make(chan struct{})
+		release := make(chan struct{})
+		q := newAsyncQueue(AsyncConfig{QueueSize: 2, BatchSize: 1, Overflow: tt.overflow}, func(dump *LoggInfo) {
+			if delivered = append(delivered, dump.Info.GetRawString()); len(delivered) == 1 {
+				close(started)
+				<-release
+			}
+		})
+		// worker阻塞在第一条日志上，之后的4条中只有2条能进入队列
+		q.put(ringEntry(0, "0"))
+		<-started
+		filled := make(chan struct{})
+		go func() {
+			defer close(filled)
+			for i := 1; i <= 4; i++ {
+				q.put(ringEntry(float64(i), strconv.Itoa(i)))
+			}
+		}()
+		if tt.overflow == OverflowBlock {
+			select {
+			case <-filled:
+				t.Errorf("%s: put did not block on a full queue", tt.name)
+			case <-time.After(20 * time.Millisecond):
+			}
+		}
+		within(t, 2*time.Second, func() {
+			if tt.overflow != OverflowBlock {
+				<-filled
+			}
+			close(release)
+			<-filled
+			q.flush()
+			q.close()
+		})
+		if got := strings.Join(delivered, ","); got != tt.wantDelivered {
+			t.Errorf("%s: delivered %q, want %q", tt.name, got, tt.wantDelivered)
+		}
+		if got := q.stats(); got != tt.wantStats {
+			t.Errorf("%s: stats %+v, want %+v", tt.name, got, tt.wantStats)
+		}
+	}
+}
+
+func TestAsyncOverflowDropBelow(t *testing.T) {
+	var delivered []LogLevel
+	started := make(chan struct{})
+	release := make(chan struct{})
+	q := newAsyncQueue(AsyncConfig{QueueSize: 1, BatchSize: 1, Overflow: OverflowDropBelow, DropBelow: LevelWarning}, func(dump *LoggInfo) {
+		if delivered = append(delivered, dump.Level); len(delivered) == 1 {
+			close(started)
+			<-release
+		}
+	})
+	q.put(&LoggInfo{Level: LevelCommon})
+	<-started
+	q.put(&LoggInfo{Level: LevelCommon})
+	// 队列已满：低于DropBelow的日志被丢弃，其余日志等待空位
+	q.put(&LoggInfo{Level: LevelDebug})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		q.put(&LoggInfo{Level: LevelError})
+	}()
+	within(t, 2*time.Second, func() {
+		close(release)
+		<-done
+		q.flush()
+		q.close()
+	})
+	if len(delivered) != 3 || delivered[2] != LevelError {
+		t.Fatalf("delivered %v", delivered)
+	}
+	if stats := q.stats(); stats.Dropped != 1 || stats.Delivered != 3 {
+		t.Fatalf("stats %+v", stats)
+	}
+}
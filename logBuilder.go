@@ -1,5 +1,7 @@
 package logger
 
+import "context"
+
 type LogAction func(...LogComponent)
 
 type LogBuilder struct {
@@ -63,6 +65,14 @@ func (builder *LogBuilder) WithContent(ctx ...LogCtx) *LogBuilder {
 	return builder.WithComponent(WithContent(ctx...))
 }
 
+func (builder *LogBuilder) WithLazyContent(f func() []LogCtx) *LogBuilder {
+	return builder.WithComponent(WithLazyContent(f))
+}
+
+func (builder *LogBuilder) WithContext(ctx context.Context) *LogBuilder {
+	return builder.WithComponent(WithContext(ctx))
+}
+
 func (builder *LogBuilder) WithBacktraceLevelDelta(level int) *LogBuilder {
 	return builder.WithComponent(WithBacktraceLevelDelta(level))
 }
@@ -95,6 +105,10 @@ func (builder *LogBuilder) WithStruct(s interface{}) *LogBuilder {
 	return builder.WithComponent(WithStruct(s))
 }
 
+func (builder *LogBuilder) WithFields(fields ...Field) *LogBuilder {
+	return builder.WithComponent(WithFields(fields...))
+}
+
 func (builder *LogBuilder) Commit() {
 	//防止回溯到Commit函数
 	builder.WithBacktraceLevelDelta(1).onCommit(builder.components...)
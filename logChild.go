@@ -0,0 +1,76 @@
+package logger
+
+import "github.com/modern-go/reflect2"
+
+// With 派生一个附带固定字段的Logger，派生Logger的每条日志都会在字段开头带上这些字段
+//
+// 派生Logger与父Logger共享历史记录、Printer、日志等级、异步队列与Formatter，沿用父Logger自定义的DefaultIO，且不会被放入全局pool，
+// 因此可以放心地为每个请求创建并在请求结束后丢弃。
+//
+// e.g.
+//
+//	reqLogger := logger.RootLogger.With(logger.String("reqId", id), logger.String("path", r.URL.Path))
+//	reqLogger.Common(logger.WithContent("request accepted"))
+func (l *Logger) With(fields ...Field) *Logger {
+	child := l.derive(l.Name)
+	child.fields = make([]Field, 0, len(l.fields)+len(fields))
+	child.fields = append(child.fields, l.fields...)
+	child.fields = append(child.fields, fields...)
+	return child
+}
+
+// Named 派生一个名称为"父名称.name"的Logger，保留父Logger已附带的字段，共享规则与With相同
+//
+// e.g.
+//
+//	poolLogger := logger.GetLogger("sys", false).Named("db").Named("pool") // <sys.db.pool>
+func (l *Logger) Named(name string) *Logger {
+	if name == "" {
+		return l.derive(l.Name)
+	}
+	if l.Name == "" {
+		return l.derive(name)
+	}
+	return l.derive(l.Name + "." + name)
+}
+
+// Parent 获取派生出当前Logger的父Logger，由GetLogger创建的Logger返回nil
+func (l *Logger) Parent() *Logger {
+	return l.parent
+}
+
+// Fields 获取当前Logger附带的固定字段
+func (l *Logger) Fields() []Field {
+	return l.fields
+}
+
+// derive 派生Logger，父Logger的DefaultIO为其自身的internalPrinter时派生Logger使用绑定自身的internalPrinter，
+// 否则沿用父Logger的DefaultIO（包括nil）
+func (l *Logger) derive(name string) *Logger {
+	child := &Logger{
+		loggerCore: l.loggerCore,
+		Name:       name,
+		logShowCur: l.logShowCur,
+		DefaultIO:  l.DefaultIO,
+		fields:     l.fields,
+		parent:     l,
+	}
+	if l.DefaultIO != nil && l.internalIO != nil && reflect2.PtrOf(l.DefaultIO) == reflect2.PtrOf(l.internalIO) {
+		child.DefaultIO = child.internalPrinter
+		child.internalIO = child.DefaultIO
+	}
+	return child
+}
+
+// withOwnFields 将Logger附带的固定字段置于本条日志的字段之前
+func (l *Logger) withOwnFields(fields []Field) []Field {
+	if len(l.fields) == 0 {
+		return fields
+	}
+	if len(fields) == 0 {
+		return l.fields
+	}
+	result := make([]Field, 0, len(l.fields)+len(fields))
+	result = append(result, l.fields...)
+	return append(result, fields...)
+}
@@ -0,0 +1,28 @@
+package logger
+
+import "testing"
+
+func TestDeriveInheritsDefaultIO(t *testing.T) {
+	var custom []string
+	parent := GetLogger(t.Name(), false)
+	parent.DefaultIO = func(info *LoggInfo) { custom = append(custom, info.from.Name) }
+	parent.With(String("k", "v")).Named("child").Common(WithContent("custom"))
+	if len(custom) != 1 || custom[0] != t.Name()+".child" {
+		t.Fatalf("custom DefaultIO not inherited: %v", custom)
+	}
+
+	parent.DefaultIO = nil
+	child := parent.With(String("k", "v"))
+	if child.DefaultIO != nil {
+		t.Fatal("nil DefaultIO not inherited")
+	}
+
+	root := GetLogger(t.Name()+".root", false)
+	named := root.Named("sub")
+	if named.DefaultIO == nil || named.internalIO == nil {
+		t.Fatal("internalPrinter not rebound to the derived logger")
+	}
+	if named.Named("deeper").internalIO == nil {
+		t.Fatal("internalPrinter not rebound across generations")
+	}
+}
@@ -1,8 +1,10 @@
 package logger
 
 import (
+	"context"
 	"fmt"
 	"github.com/fatih/structs"
+	"strings"
 )
 
 //如何向func传递默认值
@@ -16,6 +18,11 @@ type logOptions struct {
 	Sep                 string
 	End                 string
 	Cur                 string
+	Fields              []Field
+	TraceID             string
+	SpanID              string
+	// 尚未调用提取器的WithContext，由resolveContexts在确定日志等级启用后处理
+	contexts []pendingContext
 }
 
 type LogComponent interface {
@@ -33,9 +40,34 @@ func newFuncOption(f func(*logOptions)) *logOption {
 		f: f,
 	}
 }
+
+// contentOption WithContent的实现，避免为每次调用额外分配闭包
+type contentOption struct {
+	ctx []LogCtx
+}
+
+func (c *contentOption) apply(o *logOptions) {
+	o.Info = append(o.Info, c.ctx...)
+}
+
 func WithContent(ctx ...LogCtx) LogComponent {
+	return &contentOption{ctx: ctx}
+}
+
+// lazyContent 延迟构建的日志内容
+type lazyContent func() []LogCtx
+
+// WithLazyContent 添加延迟构建的日志内容，f仅在日志等级启用（或需要记录到历史）时才会被调用，
+// 适合构建开销较大的内容
+//
+// e.g.
+//
+//	l.Debug(logger.WithLazyContent(func() []logger.LogCtx { return []logger.LogCtx{"state:", dumpState()} }))
+func WithLazyContent(f func() []LogCtx) LogComponent {
 	return newFuncOption(func(o *logOptions) {
-		o.Info = append(o.Info, ctx...)
+		if f != nil {
+			o.Info = append(o.Info, lazyContent(f))
+		}
 	})
 }
 func WithBacktraceLevelDelta(level int) LogComponent {
@@ -79,11 +111,11 @@ func WithCur(cur interface{}) LogComponent {
 	})
 }
 
-//WithKVs 依据 (key1 string, value1 interface, key2 string, value2 interface{}...)参数生成结构化日志.
+// WithKVs 依据 (key1 string, value1 interface, key2 string, value2 interface{}...)参数生成结构化日志.
 //
-//Example:
+// Example:
 //
-//WithKVs("Field 'taskId' found nil in response!", "URL", req.url, "code", resp.StatusCode, "raw", string(respBytes))
+// WithKVs("Field 'taskId' found nil in response!", "URL", req.url, "code", resp.StatusCode, "raw", string(respBytes))
 func WithKVs(keyValues ...interface{}) LogComponent {
 	n := len(keyValues)
 	if n%2 != 0 {
@@ -91,30 +123,109 @@ func WithKVs(keyValues ...interface{}) LogComponent {
 		n += 1
 	}
 
-	result := ""
+	fields := make([]Field, 0, n/2)
 	for i := 0; i < n; i += 2 {
-		result += formatKV(keyValues[i], keyValues[i+1])
+		fields = append(fields, Any(fmt.Sprint(keyValues[i]), keyValues[i+1]))
 	}
 
-	return WithContent(result)
+	return withFields(fields)
 }
 
-//WithStruct 打印一个结构体.
+// WithStruct 打印一个结构体.
 //
-//NOTE: 只能访问公共字段.
+// NOTE: 只能访问公共字段.
 func WithStruct(s interface{}) LogComponent {
-	structMap := structs.Map(s)
+	return withFields(structFields(s))
+}
+
+// WithFields 添加类型化的结构化字段.
+//
+// Example:
+//
+// WithFields(logger.String("url", req.url), logger.Int("code", resp.StatusCode), logger.Err(err))
+func WithFields(fields ...Field) LogComponent {
+	return withFields(fields)
+}
 
-	result := ""
-	for key, value := range structMap {
-		result += formatKV(key, value)
+// contextOption WithContext的实现
+type contextOption struct {
+	ctx context.Context
+}
+
+func (c *contextOption) apply(o *logOptions) {
+	if c.ctx != nil {
+		o.contexts = append(o.contexts, pendingContext{ctx: c.ctx, at: len(o.Fields)})
 	}
+}
+
+// pendingContext 尚未提取字段的WithContext，at为应用该组件时Fields的长度
+type pendingContext struct {
+	ctx context.Context
+	at  int
+}
 
-	return WithContent(result)
+// resolveContexts 调用提取器，将WithContext的字段插入到该组件在Fields中对应的位置并填充TraceID与SpanID，
+// 需在确定日志等级启用（或需要记录到历史）后调用，使未启用等级的日志不调用提取器
+func (o *logOptions) resolveContexts() {
+	if len(o.contexts) == 0 {
+		return
+	}
+	fields := make([]Field, 0, len(o.Fields)+4*len(o.contexts))
+	last := 0
+	for _, p := range o.contexts {
+		fields = append(fields, o.Fields[last:p.at]...)
+		last = p.at
+		fields = contextFields(p.ctx, fields)
+		if traceID, spanID := traceFromContext(p.ctx); traceID != "" {
+			o.TraceID, o.SpanID = traceID, spanID
+		}
+	}
+	o.Fields = append(fields, o.Fields[last:]...)
 }
 
-func formatKV(key interface{}, value interface{}) string {
-	return fmt.Sprintf("\n\t- %-10v= %v", key, value)
+// WithContext 将ctx通过ContextWithFields携带的字段，以及RegisterContextExtractor注册的提取器从ctx中提取的字段（请求ID、用户ID等）
+// 添加到本条日志的结构化字段中，并通过SetTraceExtractor设置的提取器填充TraceID与SpanID；
+// 提取器仅在日志等级启用（或保留策略要求记录未启用等级的日志）时才会被调用，Logger.Log同样如此
+//
+// Example:
+//
+// logger.FromContext(ctx).Common(logger.WithContent("request accepted"), logger.WithContext(ctx))
+func WithContext(ctx context.Context) LogComponent {
+	return &contextOption{ctx: ctx}
+}
+
+func withFields(fields []Field) LogComponent {
+	return newFuncOption(func(o *logOptions) {
+		o.Fields = append(o.Fields, fields...)
+	})
+}
+
+// structFields 按声明顺序展开结构体的公共字段，字段名遵循`structs`标签
+func structFields(s interface{}) []Field {
+	st := structs.New(s)
+	result := make([]Field, 0)
+	for _, f := range st.Fields() {
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name()
+		if tag := f.Tag(st.TagName); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			if idx := strings.IndexByte(tag, ','); idx >= 0 {
+				if strings.Contains(tag[idx:], "omitempty") && f.IsZero() {
+					continue
+				}
+				tag = tag[:idx]
+			}
+			if tag != "" {
+				name = tag
+			}
+		}
+		result = append(result, Any(name, f.Value()))
+	}
+	return result
 }
 
 func defaultOptions() logOptions {
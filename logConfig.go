@@ -53,42 +53,87 @@ func (s *SliceTime) And(hour, min, sec int, timeZone ...*time.Location) *SliceTi
 	return s
 }
 
+// LockMode 全局日志文件已被其他进程使用时的处理方式，锁为日志文件旁"<Name>.lock"文件上的建议锁（Linux等系统上为flock）
+type LockMode uint8
+
+const (
+	// LockNone 不加锁（默认）
+	LockNone LockMode = iota
+	// LockFail 返回GlobLogOpLock错误（InitGlobLogWithConfig等待输入回车后退出）
+	LockFail
+	// LockWait 等待其他进程释放锁
+	LockWait
+	// LockPerPID 改用带有进程号的日志文件名，如globlog.1234.log；此时已退出的进程留下的globlog.<pid>.log
+	// 会按不带进程号的文件名归档，其锁文件会被删除
+	LockPerPID
+)
+
+// Durability 日志文件写入的持久化方式
+type Durability uint8
+
+const (
+	// DurabilitySync 以O_SYNC打开文件，每条日志写入后即落盘（默认）
+	DurabilitySync Durability = iota
+	// DurabilityWrite 每条日志直接写入文件但不等待落盘，按SyncEvery与SyncLevel执行fsync
+	DurabilityWrite
+	// DurabilityBuffered 日志先写入内存缓冲区，缓冲区满、每隔FlushInterval或写入SyncLevel等级的日志时写入文件，按SyncEvery与SyncLevel执行fsync
+	DurabilityBuffered
+)
+
 type Config struct {
 	// 日志名称
 	Name string
 	// 日志插入首行描述
 	Desc string
-	// 过时日志存储路径
+	// 过时日志存储路径，设置后ArchiveName不再生效
 	OldLogPath func(info os.FileInfo) string
+	// 归档文件名模板，默认为DefaultArchiveName，可使用{name}（日志文件名去除扩展名）、{date:layout}（日志文件修改时间，
+	// 省略layout时为2006-01-02）、{seq}（在已有归档的最大序号上加1）、{pid}与{host}
+	ArchiveName string
 	// 日志最大存储时间（超时切片，优先生效）
 	MaxLogTime time.Duration
 	// 日志定时切片
 	SliceWhen SliceTime
-}
+	// 按cron表达式或日历规则切片（见ParseCron、Hourly、Weekly、Monthly），与SliceWhen同时设置时取较早者；
+	// 永远不会触发的计划在初始化时返回Op为GlobLogOpSchedule的错误
+	Schedule Schedule
+	// 定时切片使用的时钟，默认为SystemClock
+	Clock Clock
+	// 日志文件最大字节数，写入将超过该大小时先切片再写入（整行写入新文件），与定时切片可同时生效
+	MaxSize int64
+	// 归档日志所在目录，默认为"logs"，用于生成归档路径以及压缩与清理归档
+	ArchiveDir string
+	// 归档日志压缩方式，为nil时不压缩，切片后在后台压缩
+	Compress Compressor
+	// 最多保留的归档文件数
+	MaxArchives int
+	// 归档文件的最大总字节数
+	MaxArchiveSize int64
+	// 归档文件的最长保留时间（按修改时间计算）
+	MaxArchiveAge time.Duration
+	// 日志文件写入的持久化方式
+	Durability Durability
+	// DurabilityBuffered时的缓冲区大小，默认为64KB
+	BufferSize int
+	// DurabilityBuffered时定时将缓冲区写入文件的间隔，默认为1秒
+	FlushInterval time.Duration
+	// 非DurabilitySync时每写入SyncEvery条日志执行一次fsync，为0时不按条数执行
+	SyncEvery int
+	// 非DurabilitySync时写入这些等级的日志后立即写入文件并执行fsync，默认为LevelFatal|LevelError
+	SyncLevel LogLevel
+	// 日志文件已被其他进程使用时的处理方式
+	Lock LockMode
+	// 收到ReopenSignal时调用ReopenGlobLog重新打开日志文件，用于配合logrotate等外部工具
+	ReopenOnSignal bool
+	// 重新打开日志文件的信号，默认为SIGHUP
+	ReopenSignal os.Signal
+	// 以管道接管标准错误并按行转换为日志（而非直接将标准错误重定向到日志文件），panic等崩溃信息仍直接写入日志文件（需要Go 1.23及以上）
+	CaptureStderr bool
+	// 接管标准错误时输出日志的Logger，默认为RootLogger
+	StderrLogger *Logger
+	// 接管标准错误时输出日志的等级，默认为LevelError
+	StderrLevel LogLevel
 
-func findNextByWhen(cfg Config) time.Duration {
-	if len(cfg.SliceWhen) != 0 {
-		now := time.Now()
-		minD := time.Hour * 24
-		for _, v := range cfg.SliceWhen {
-			t := time.Date(now.Year(), now.Month(), now.Day(), v.Hour, v.Min, v.Second, 0, v.TZone)
-			if t.After(now) {
-				minD = min(minD, t.Sub(now))
-			} else {
-				minD = min(minD, t.Add(time.Hour*24).Sub(now))
-			}
-		}
-		return minD
-	}
-	if cfg.MaxLogTime != 0 {
-		return cfg.MaxLogTime
-	}
-	return time.Hour * 24
-}
-
-func min(d time.Duration, sub time.Duration) time.Duration {
-	if d > sub {
-		return sub
-	}
-	return d
+	// 未设置OldLogPath时由ArchiveName生成
+	archiveName *archiveTemplate
 }
@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"context"
+	"sync"
+)
+
+// contextKey 本包存入context的值的键
+type contextKey int
+
+const (
+	loggerContextKey contextKey = iota
+	fieldsContextKey
+)
+
+// NewContext 返回携带l的ctx副本，可通过FromContext取回，通常在请求入口处存入附带请求字段的派生Logger
+//
+// e.g.
+//
+//	ctx = logger.NewContext(ctx, logger.RootLogger.With(logger.String("reqId", id)))
+//	...
+//	logger.FromContext(ctx).Common(logger.WithContent("request accepted"))
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerContextKey, l)
+}
+
+// FromContext 取出NewContext存入的Logger，ctx为nil或其中没有Logger时返回RootLogger
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerContextKey).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return RootLogger
+}
+
+// ContextWithFields 返回携带fields的ctx副本，fields追加在ctx已携带的字段之后，通过WithContext输出
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if len(fields) == 0 {
+		return ctx
+	}
+	prev := ContextFields(ctx)
+	merged := make([]Field, 0, len(prev)+len(fields))
+	merged = append(merged, prev...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+// ContextFields 获取ctx通过ContextWithFields携带的字段
+func ContextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey).([]Field)
+	return fields
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Context Extractor Functions
+
+// ContextExtractor 从context中提取结构化字段，ctx中没有对应的值时返回nil
+type ContextExtractor func(ctx context.Context) []Field
+
+// namedExtractor 已注册的ContextExtractor
+type namedExtractor struct {
+	name    string
+	extract ContextExtractor
+}
+
+var (
+	extractorMu sync.RWMutex
+	// extractors 按注册顺序保存，写入时整体替换，读取时无需复制
+	extractors []namedExtractor
+)
+
+// RegisterContextExtractor 以name注册一个ContextExtractor，WithContext按注册顺序调用全部提取器；
+// 同名的提取器会被替换（保留原有顺序），extract为nil时移除该提取器，可并发调用
+//
+// e.g.
+//
+//	logger.RegisterContextExtractor("userId", func(ctx context.Context) []logger.Field {
+//		if uid, ok := ctx.Value(userIDKey{}).(int64); ok {
+//			return []logger.Field{logger.Int64("userId", uid)}
+//		}
+//		return nil
+//	})
+func RegisterContextExtractor(name string, extract ContextExtractor) {
+	extractorMu.Lock()
+	defer extractorMu.Unlock()
+	next := make([]namedExtractor, 0, len(extractors)+1)
+	replaced := false
+	for _, e := range extractors {
+		if e.name != name {
+			next = append(next, e)
+		} else if extract != nil {
+			next = append(next, namedExtractor{name: name, extract: extract})
+			replaced = true
+		}
+	}
+	if !replaced && extract != nil {
+		next = append(next, namedExtractor{name: name, extract: extract})
+	}
+	extractors = next
+}
+
+// RegisterContextKey 注册一个以field为名称的提取器，将ctx.Value(key)作为字段输出，值为nil时不输出
+//
+// e.g.
+//
+//	logger.RegisterContextKey("reqId", requestIDKey{})
+func RegisterContextKey(field string, key interface{}) {
+	RegisterContextExtractor(field, func(ctx context.Context) []Field {
+		if v := ctx.Value(key); v != nil {
+			return []Field{Any(field, v)}
+		}
+		return nil
+	})
+}
+
+// contextFields 返回ctx携带的字段与全部提取器提取的字段
+func contextFields(ctx context.Context, fields []Field) []Field {
+	if ctx == nil {
+		return fields
+	}
+	fields = append(fields, ContextFields(ctx)...)
+	extractorMu.RLock()
+	list := extractors
+	extractorMu.RUnlock()
+	for _, e := range list {
+		fields = append(fields, e.extract(ctx)...)
+	}
+	return fields
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Trace Context Functions
+
+// TraceExtractor 从context中取得当前span的W3C trace ID（32位小写十六进制）与span ID（16位小写十六进制），
+// 没有有效的span时返回空字符串
+type TraceExtractor func(ctx context.Context) (traceID string, spanID string)
+
+var traceExtractor TraceExtractor
+
+// SetTraceExtractor 设置WithContext与SlogHandler使用的trace提取器，传入nil时不再提取，可并发调用；
+// 本包不依赖任何追踪库，OpenTelemetry可使用github.com/fexli/logger/otellog提供的提取器
+func SetTraceExtractor(extract TraceExtractor) {
+	extractorMu.Lock()
+	traceExtractor = extract
+	extractorMu.Unlock()
+}
+
+// traceFromContext 使用trace提取器从ctx中取得trace ID与span ID
+func traceFromContext(ctx context.Context) (string, string) {
+	if ctx == nil {
+		return "", ""
+	}
+	extractorMu.RLock()
+	extract := traceExtractor
+	extractorMu.RUnlock()
+	if extract == nil {
+		return "", ""
+	}
+	return extract(ctx)
+}
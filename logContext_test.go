@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestContextExtractorsOnlyForEnabledLevels(t *testing.T) {
+	var calls int64
+	RegisterContextExtractor(t.Name(), func(ctx context.Context) []Field {
+		atomic.AddInt64(&calls, 1)
+		return []Field{String("extracted", "yes")}
+	})
+	defer RegisterContextExtractor(t.Name(), nil)
+
+	l := GetLogger(t.Name(), false)
+	l.SetLogLevel(LevelAtLeast(LevelWarning))
+	ctx := ContextWithFields(context.Background(), String("carried", "yes"))
+	l.Debug(WithContext(ctx))
+	l.Log(WithLevel(LevelDebug), WithContext(ctx))
+	l.Log(WithContext(ctx), WithLevel(LevelCommon))
+	if got := atomic.LoadInt64(&calls); got != 0 {
+		t.Fatalf("extractor called %d times for disabled levels", got)
+	}
+
+	l.Log(WithFields(String("first", "yes")), WithContext(ctx), WithLevel(LevelWarning), WithFields(String("last", "yes")))
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("extractor called %d times, want 1", got)
+	}
+	var keys []string
+	for _, f := range l.GetLatestLog().Fields {
+		keys = append(keys, f.Key)
+	}
+	if want := []string{"first", "carried", "extracted", "last"}; strings.Join(keys, ",") != strings.Join(want, ",") {
+		t.Fatalf("fields %v, want %v", keys, want)
+	}
+}
+
+type traceKey struct{}
+
+func TestTraceOutput(t *testing.T) {
+	const traceID, spanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	SetTraceExtractor(func(ctx context.Context) (string, string) {
+		ids, _ := ctx.Value(traceKey{}).([2]string)
+		return ids[0], ids[1]
+	})
+	defer SetTraceExtractor(nil)
+	l := GetLogger(t.Name(), false)
+	tests := []struct {
+		name       string
+		ids        [2]string
+		wantText   string
+		wantLogfmt string
+	}{
+		{"trace and span", [2]string{traceID, spanID}, "]m [trace=4bf92f35 span=00f067aa]", " trace_id=" + traceID + " span_id=" + spanID + " msg=m"},
+		{"trace only", [2]string{traceID, ""}, "]m [trace=4bf92f35]", " trace_id=" + traceID + " msg=m"},
+		{"no trace", [2]string{}, "]m", " msg=m"},
+	}
+	for _, tt := range tests {
+		ctx := context.WithValue(context.Background(), traceKey{}, tt.ids)
+		_, obj := formatJSON(t, l, WithContent("m"), WithContext(ctx))
+		dump := l.GetLatestLog()
+		if got := (TextFormatter{}).Format(l, dump).GetRawString(); !strings.HasSuffix(got, tt.wantText) {
+			t.Errorf("%s: text %q, want suffix %q", tt.name, got, tt.wantText)
+		}
+		if got := (LogfmtFormatter{}).Format(l, dump).GetRawString(); !strings.HasSuffix(got, tt.wantLogfmt) {
+			t.Errorf("%s: logfmt %q, want suffix %q", tt.name, got, tt.wantLogfmt)
+		}
+		for key, want := range map[string]string{"trace_id": tt.ids[0], "span_id": tt.ids[1]} {
+			got, ok := obj[key]
+			if want == "" && ok || want != "" && got != want {
+				t.Errorf("%s: JSON %s = %v, want %q", tt.name, key, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FieldType 结构化字段的值类型
+type FieldType uint8
+
+const (
+	FieldAny FieldType = iota
+	FieldString
+	FieldInt
+	FieldUint
+	FieldFloat
+	FieldBool
+	FieldDuration
+	FieldTime
+	FieldError
+	FieldObject
+)
+
+// Field 结构化日志字段，由WithKVs、WithStruct、WithFields产生，按添加顺序保存在LoggInfo.Fields中
+//
+// 字段值按类型分别存放以避免装箱：整数、布尔与时长存放于Integer，浮点数存放于Float，字符串存放于Str，
+// 其余类型（time.Time、error、嵌套对象的[]Field以及任意值）存放于Iface，可通过Value()统一获取
+type Field struct {
+	Key     string
+	Type    FieldType
+	Integer int64
+	Float   float64
+	Str     string
+	Iface   interface{}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Field Creators
+
+// String 创建字符串字段
+func String(key string, val string) Field {
+	return Field{Key: key, Type: FieldString, Str: val}
+}
+
+// Int 创建整数字段
+func Int(key string, val int) Field {
+	return Field{Key: key, Type: FieldInt, Integer: int64(val)}
+}
+
+// Int64 创建整数字段
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Type: FieldInt, Integer: val}
+}
+
+// Uint64 创建无符号整数字段
+func Uint64(key string, val uint64) Field {
+	return Field{Key: key, Type: FieldUint, Integer: int64(val)}
+}
+
+// Float64 创建浮点数字段
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Type: FieldFloat, Float: val}
+}
+
+// Bool 创建布尔字段
+func Bool(key string, val bool) Field {
+	f := Field{Key: key, Type: FieldBool}
+	if val {
+		f.Integer = 1
+	}
+	return f
+}
+
+// Duration 创建时长字段
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Type: FieldDuration, Integer: int64(val)}
+}
+
+// Time 创建时间字段
+func Time(key string, val time.Time) Field {
+	return Field{Key: key, Type: FieldTime, Iface: val}
+}
+
+// Err 创建键为"error"的错误字段
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr 创建指定键的错误字段，err为nil时字段值为nil
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{Key: key, Type: FieldAny}
+	}
+	return Field{Key: key, Type: FieldError, Iface: err}
+}
+
+// Object 创建嵌套对象字段，嵌套字段同样保持添加顺序
+func Object(key string, fields ...Field) Field {
+	return Field{Key: key, Type: FieldObject, Iface: fields}
+}
+
+// Any 依据值的实际类型创建字段，无法识别的类型以FieldAny保存
+func Any(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case Field:
+		return Object(key, v)
+	case []Field:
+		return Object(key, v...)
+	case string:
+		return String(key, v)
+	case int:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case int32:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int8:
+		return Int64(key, int64(v))
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case error:
+		return NamedErr(key, v)
+	}
+	return Field{Key: key, Type: FieldAny, Iface: val}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Field Functions
+
+// Value 以interface{}形式返回字段值，嵌套对象返回[]Field
+func (f Field) Value() interface{} {
+	switch f.Type {
+	case FieldString:
+		return f.Str
+	case FieldInt:
+		return f.Integer
+	case FieldUint:
+		return uint64(f.Integer)
+	case FieldFloat:
+		return f.Float
+	case FieldBool:
+		return f.Integer == 1
+	case FieldDuration:
+		return time.Duration(f.Integer)
+	}
+	return f.Iface
+}
+
+// Fields 返回嵌套对象字段的子字段，非对象字段返回nil
+func (f Field) Fields() []Field {
+	if f.Type != FieldObject {
+		return nil
+	}
+	fields, _ := f.Iface.([]Field)
+	return fields
+}
+
+// textValue 返回字段值的文本形式，用于TextFormatter与LogfmtFormatter
+func (f Field) textValue() string {
+	switch f.Type {
+	case FieldString:
+		return f.Str
+	case FieldInt:
+		return strconv.FormatInt(f.Integer, 10)
+	case FieldUint:
+		return strconv.FormatUint(uint64(f.Integer), 10)
+	case FieldFloat:
+		return strconv.FormatFloat(f.Float, 'g', -1, 64)
+	case FieldBool:
+		return strconv.FormatBool(f.Integer == 1)
+	case FieldDuration:
+		return time.Duration(f.Integer).String()
+	case FieldError:
+		return f.Iface.(error).Error()
+	case FieldObject:
+		sub := f.Fields()
+		parts := make([]string, 0, len(sub))
+		for _, s := range sub {
+			parts = append(parts, s.Key+"="+s.textValue())
+		}
+		return "{" + strings.Join(parts, " ") + "}"
+	}
+	return fmt.Sprintf("%v", f.Iface)
+}
+
+// appendJSON 将字段值编码为JSON追加到b
+func (f Field) appendJSON(b []byte) []byte {
+	switch f.Type {
+	case FieldString:
+		return appendJSONString(b, f.Str)
+	case FieldInt:
+		return strconv.AppendInt(b, f.Integer, 10)
+	case FieldUint:
+		return strconv.AppendUint(b, uint64(f.Integer), 10)
+	case FieldFloat:
+		if math.IsNaN(f.Float) || math.IsInf(f.Float, 0) {
+			return appendJSONString(b, strconv.FormatFloat(f.Float, 'g', -1, 64))
+		}
+		return strconv.AppendFloat(b, f.Float, 'g', -1, 64)
+	case FieldBool:
+		return strconv.AppendBool(b, f.Integer == 1)
+	case FieldDuration:
+		return appendJSONString(b, time.Duration(f.Integer).String())
+	case FieldTime:
+		return appendJSONString(b, f.Iface.(time.Time).Format(time.RFC3339Nano))
+	case FieldObject:
+		b = append(b, '{')
+		for i, s := range f.Fields() {
+			if i > 0 {
+				b = append(b, ',')
+			}
+			b = appendJSONString(b, s.Key)
+			b = append(b, ':')
+			b = s.appendJSON(b)
+		}
+		return append(b, '}')
+	}
+	return appendJSONValue(b, f.Iface)
+}
+
+// MarshalJSON 以{"key":...,"value":...}形式编码字段，用于序列化GetLogs的结果
+func (f Field) MarshalJSON() ([]byte, error) {
+	b := make([]byte, 0, 32)
+	b = append(b, `{"key":`...)
+	b = appendJSONString(b, f.Key)
+	b = append(b, `,"value":`...)
+	b = f.appendJSON(b)
+	return append(b, '}'), nil
+}
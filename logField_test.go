@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFieldEncoding(t *testing.T) {
+	ts := time.Date(2024, 3, 1, 9, 8, 7, 0, time.UTC)
+	tests := []struct {
+		name     string
+		field    Field
+		wantType FieldType
+		wantText string
+		wantJSON string
+	}{
+		{"string", String("k", `a "b"`), FieldString, `a "b"`, `"a \"b\""`},
+		{"int", Int("k", -3), FieldInt, "-3", "-3"},
+		{"uint64 max", Uint64("k", math.MaxUint64), FieldUint, "18446744073709551615", "18446744073709551615"},
+		{"float", Float64("k", 1.5), FieldFloat, "1.5", "1.5"},
+		{"float NaN", Float64("k", math.NaN()), FieldFloat, "NaN", `"NaN"`},
+		{"float Inf", Float64("k", math.Inf(1)), FieldFloat, "+Inf", `"+Inf"`},
+		{"bool", Bool("k", true), FieldBool, "true", "true"},
+		{"duration", Duration("k", 1500*time.Millisecond), FieldDuration, "1.5s", `"1.5s"`},
+		{"time", Time("k", ts), FieldTime, "2024-03-01 09:08:07 +0000 UTC", `"2024-03-01T09:08:07Z"`},
+		{"error", Err(errors.New("boom")), FieldError, "boom", `"boom"`},
+		{"nil error", Err(nil), FieldAny, "<nil>", "null"},
+		{"object", Object("k", Int("a", 1), String("b", "x")), FieldObject, "{a=1 b=x}", `{"a":1,"b":"x"}`},
+		{"any slice", Any("k", []int{1, 2}), FieldAny, "[1 2]", "[1,2]"},
+	}
+	for _, tt := range tests {
+		if tt.field.Type != tt.wantType {
+			t.Errorf("%s: type %d, want %d", tt.name, tt.field.Type, tt.wantType)
+		}
+		if got := tt.field.textValue(); got != tt.wantText {
+			t.Errorf("%s: text %q, want %q", tt.name, got, tt.wantText)
+		}
+		if got := string(tt.field.appendJSON(nil)); got != tt.wantJSON {
+			t.Errorf("%s: JSON %s, want %s", tt.name, got, tt.wantJSON)
+		}
+	}
+}
+
+func TestAnyFieldTypes(t *testing.T) {
+	tests := []struct {
+		val       interface{}
+		wantType  FieldType
+		wantValue interface{}
+	}{
+		{"s", FieldString, "s"},
+		{int8(-8), FieldInt, int64(-8)},
+		{int32(32), FieldInt, int64(32)},
+		{uint16(16), FieldUint, uint64(16)},
+		{uint8(8), FieldUint, uint64(8)},
+		{2.5, FieldFloat, 2.5},
+		{false, FieldBool, false},
+		{time.Second, FieldDuration, time.Second},
+		{float32(1), FieldAny, float32(1)},
+		{nil, FieldAny, nil},
+	}
+	for _, tt := range tests {
+		f := Any("k", tt.val)
+		if f.Type != tt.wantType || f.Value() != tt.wantValue {
+			t.Errorf("Any(%#v): type %d value %#v, want type %d value %#v", tt.val, f.Type, f.Value(), tt.wantType, tt.wantValue)
+		}
+	}
+	if sub := Any("k", Int("a", 1)).Fields(); len(sub) != 1 || sub[0].Key != "a" {
+		t.Errorf("Any(Field) did not nest: %v", sub)
+	}
+}
+
+func TestNestedFieldOutput(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	user := Object("user", Int("id", 7), Object("name", String("first", "Ada"), String("last", "L")))
+	line := formatLogfmt(l, WithContent("m"), WithFields(user))
+	if want := " msg=m user.id=7 user.name.first=Ada user.name.last=L"; !strings.HasSuffix(line, want) {
+		t.Errorf("logfmt: got %q, want suffix %q", line, want)
+	}
+	_, obj := formatJSON(t, l, WithContent("m"), WithFields(user))
+	name, _ := obj["user"].(map[string]interface{})["name"].(map[string]interface{})
+	if name["first"] != "Ada" || name["last"] != "L" {
+		t.Errorf("JSON: nested object %v", obj["user"])
+	}
+}
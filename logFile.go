@@ -0,0 +1,370 @@
+package logger
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// logFile 日志文件及其切片状态，由全局日志与FileSink共用，非并发安全，由所属的锁保护
+type logFile struct {
+	file   *os.File
+	buf    *bufio.Writer
+	config Config
+	size   int64
+	lock   *os.File
+	// 上次fsync后写入的日志条数
+	unsynced int
+	// 定时切片等无法返回错误的操作中发生的错误，由下一次write返回
+	err error
+}
+
+// open 获取日志文件锁，归档上次运行留下的日志文件并打开新的日志文件，
+// 使用LockPerPID时会修改current.Name，失败时不修改f
+func (f *logFile) open(current *Config) *GlobLogError {
+	lock, gerr := lockLogFile(current)
+	if gerr != nil {
+		return gerr
+	}
+	return f.openLocked(current, lock)
+}
+
+// openLocked 与open相同，但使用已获取的日志文件锁lock（未加锁时为nil），失败时释放lock
+func (f *logFile) openLocked(current *Config, lock *os.File) (gerr *GlobLogError) {
+	defer func() {
+		if gerr != nil {
+			releaseLock(lock)
+		}
+	}()
+	if gerr = checkSchedule(*current); gerr != nil {
+		return gerr
+	}
+	// 与早期版本相同，归档目录创建失败只在确实需要归档上次运行留下的日志文件时才视为错误
+	mkdirErr := os.MkdirAll(current.ArchiveDir, 0764)
+	archived := ""
+	if info, err := os.Stat(current.Name); info != nil && err == nil {
+		archived = archivePath(*current, info)
+		if err = os.Rename(current.Name, archived); err != nil {
+			if mkdirErr != nil {
+				return &GlobLogError{Op: GlobLogOpMkdir, Path: current.ArchiveDir, Err: mkdirErr}
+			}
+			return &GlobLogError{Op: GlobLogOpRename, Path: current.Name, Err: err}
+		}
+	}
+	file, err := os.OpenFile(current.Name, openFlag(*current, os.O_CREATE|os.O_TRUNC|os.O_WRONLY), 0764)
+	if err != nil {
+		return &GlobLogError{Op: GlobLogOpOpen, Path: current.Name, Err: err}
+	}
+	size, err := writeHeader(file, current.Desc)
+	if err != nil {
+		_ = file.Close()
+		return &GlobLogError{Op: GlobLogOpHeader, Path: current.Name, Err: err}
+	}
+	f.config, f.size, f.lock = *current, size, lock
+	f.setFile(file)
+	go maintainArchive(*current, archived)
+	return nil
+}
+
+// openFlag 为flag加上Durability对应的标志
+func openFlag(current Config, flag int) int {
+	if current.Durability == DurabilitySync {
+		flag |= os.O_SYNC
+	}
+	return flag
+}
+
+// setFile 改用file写入日志，DurabilityBuffered时为其创建缓冲区
+func (f *logFile) setFile(file *os.File) {
+	f.file, f.buf, f.unsynced = file, nil, 0
+	if file != nil && f.config.Durability == DurabilityBuffered {
+		f.buf = bufio.NewWriterSize(file, f.config.BufferSize)
+	}
+}
+
+// writeHeader 写入Desc首行描述，desc为空时不写入，返回写入的字节数
+func writeHeader(file *os.File, desc string) (int64, error) {
+	if desc == "" {
+		return 0, nil
+	}
+	n, err := file.Write([]byte(desc + " Running Log [Started At " + time.Now().String() + "]\n"))
+	return int64(n), err
+}
+
+// lockLogFile 按current.Lock获取日志文件锁，返回持有锁的文件（未加锁时为nil）
+func lockLogFile(current *Config) (*os.File, *GlobLogError) {
+	if current.Lock == LockNone {
+		return nil, nil
+	}
+	lock, err := tryLockFile(current.Name, current.Lock == LockWait)
+	if err == ErrGlobLogLocked && current.Lock == LockPerPID {
+		base := *current
+		defer archiveStalePerPID(base)
+		ext := path.Ext(current.Name)
+		current.Name = strings.TrimSuffix(current.Name, ext) + "." + strconv.Itoa(os.Getpid()) + ext
+		if current.archiveName != nil {
+			current.archiveName = newArchiveTemplate(current.ArchiveDir, current.ArchiveName, current.Name)
+			current.OldLogPath = current.archiveName.path
+		}
+		lock, err = tryLockFile(current.Name, false)
+	}
+	if err != nil && err != errLockUnsupported {
+		return nil, &GlobLogError{Op: GlobLogOpLock, Path: current.Name + ".lock", Err: err}
+	}
+	return lock, nil
+}
+
+// archiveStalePerPID 归档已退出的进程以LockPerPID留下的日志文件（其锁文件可以加锁）并删除其锁文件，
+// 归档时使用不带进程号的日志文件名，使其与普通归档一起压缩与清理
+func archiveStalePerPID(base Config) {
+	dir, file := filepath.Split(base.Name)
+	ext := path.Ext(file)
+	stem, suffix := strings.TrimSuffix(file, ext)+".", ext+".lock"
+	if dir == "" {
+		dir = "."
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	self := strconv.Itoa(os.Getpid())
+	for _, e := range entries {
+		n := e.Name()
+		if !strings.HasPrefix(n, stem) || !strings.HasSuffix(n, suffix) || len(n) <= len(stem)+len(suffix) {
+			continue
+		}
+		pid := n[len(stem) : len(n)-len(suffix)]
+		if pid == self || strings.Trim(pid, "0123456789") != "" {
+			continue
+		}
+		name := filepath.Join(dir, stem+pid+ext)
+		lock, err := tryLockFile(name, false)
+		if err != nil {
+			// 进程仍在运行
+			continue
+		}
+		if info, err := os.Stat(name); err == nil {
+			_ = os.MkdirAll(base.ArchiveDir, 0764)
+			if archived := archivePath(base, info); os.Rename(name, archived) == nil {
+				go maintainArchive(base, archived)
+			}
+		}
+		// Windows上无法删除仍然打开的文件，因此先释放锁
+		_ = lock.Close()
+		_ = os.Remove(name + ".lock")
+	}
+}
+
+// tryLockFile 打开name对应的锁文件并加锁
+func tryLockFile(name string, wait bool) (*os.File, error) {
+	f, err := os.OpenFile(name+".lock", os.O_CREATE|os.O_RDWR, 0764)
+	if err != nil {
+		return nil, err
+	}
+	if err = lockFile(f, wait); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// rotate 切片当前日志文件；归档失败时以追加方式重新打开原文件，保证后续日志不丢失，归档成功后在后台压缩并清理归档。
+// 新文件无法打开时不再写入文件，写入首行描述失败时仍使用新文件，错误均由下一次write返回
+func (f *logFile) rotate() {
+	current := f.config
+	info, err := os.Stat(current.Name)
+	if err != nil {
+		return
+	}
+	if f.file != nil {
+		_ = f.flush()
+		fr := f.file
+		f.setFile(nil)
+		fr.Close()
+	}
+	flag := openFlag(current, os.O_CREATE|os.O_TRUNC|os.O_WRONLY)
+	archived := archivePath(current, info)
+	if err = os.Rename(current.Name, archived); err != nil {
+		flag = openFlag(current, os.O_CREATE|os.O_APPEND|os.O_WRONLY)
+	} else {
+		go maintainArchive(current, archived)
+	}
+	file, e := os.OpenFile(current.Name, flag, 0764)
+	if e != nil {
+		f.err = &GlobLogError{Op: GlobLogOpOpen, Path: current.Name, Err: e}
+		return
+	}
+	f.size = 0
+	if flag&os.O_APPEND != 0 {
+		f.size = info.Size()
+	} else if f.size, err = writeHeader(file, current.Desc); err != nil {
+		f.err = &GlobLogError{Op: GlobLogOpHeader, Path: current.Name, Err: err}
+	}
+	f.setFile(file)
+}
+
+// archivePath 生成归档路径，OldLogPath生成的路径已存在时重新生成，避免覆盖已有归档
+func archivePath(current Config, info os.FileInfo) string {
+	target := current.OldLogPath(info)
+	for i := 0; i < 8; i++ {
+		if _, err := os.Stat(target); os.IsNotExist(err) {
+			break
+		}
+		target = current.OldLogPath(info)
+	}
+	return target
+}
+
+// reopen 以追加方式重新打开日志文件并写入Desc首行描述，返回的文件需通过replace替换当前文件
+func (f *logFile) reopen() (*os.File, *GlobLogError) {
+	current := f.config
+	file, err := os.OpenFile(current.Name, openFlag(current, os.O_CREATE|os.O_APPEND|os.O_WRONLY), 0764)
+	if err != nil {
+		return nil, &GlobLogError{Op: GlobLogOpOpen, Path: current.Name, Err: err}
+	}
+	if _, err = writeHeader(file, current.Desc); err != nil {
+		_ = file.Close()
+		return nil, &GlobLogError{Op: GlobLogOpHeader, Path: current.Name, Err: err}
+	}
+	return file, nil
+}
+
+// replace 关闭当前文件并改用file
+func (f *logFile) replace(file *os.File) {
+	if f.file != nil {
+		_ = f.flush()
+		_ = f.file.Close()
+	}
+	f.setFile(file)
+	f.err = nil
+	f.size = 0
+	if info, err := file.Stat(); err == nil {
+		f.size = info.Size()
+	}
+}
+
+// write 写入一行日志，写入将超过MaxSize时先切片，写入失败时关闭文件并返回错误；
+// 非DurabilitySync时按SyncEvery与SyncLevel执行fsync。切片中发生的错误由本次（或定时切片后的下一次）write返回，
+// 文件因此关闭时之后的日志不再写入文件，错误只返回一次
+func (f *logFile) write(level LogLevel, info []byte) error {
+	if f.config.MaxSize > 0 && f.size > 0 && f.size+int64(len(info)) > f.config.MaxSize {
+		f.rotate()
+	}
+	if f.file == nil {
+		return f.takeErr()
+	}
+	var n int
+	var e error
+	if f.buf != nil {
+		n, e = f.buf.Write(info)
+	} else {
+		n, e = f.file.Write(info)
+	}
+	f.size += int64(n)
+	if e == nil && f.config.Durability != DurabilitySync {
+		f.unsynced++
+		if level&f.config.SyncLevel != 0 || (f.config.SyncEvery > 0 && f.unsynced >= f.config.SyncEvery) {
+			e = f.sync()
+		}
+	}
+	if e != nil {
+		_ = f.file.Close()
+		f.setFile(nil)
+		return e
+	}
+	return f.takeErr()
+}
+
+// takeErr 返回并清除切片中发生的错误
+func (f *logFile) takeErr() error {
+	err := f.err
+	f.err = nil
+	return err
+}
+
+// flush 将缓冲区中的日志写入文件
+func (f *logFile) flush() error {
+	if f.buf == nil {
+		return nil
+	}
+	return f.buf.Flush()
+}
+
+// sync 将缓冲区中的日志写入文件并执行fsync，DurabilitySync时无需fsync
+func (f *logFile) sync() error {
+	if f.file == nil {
+		return nil
+	}
+	if err := f.flush(); err != nil {
+		return err
+	}
+	f.unsynced = 0
+	if f.config.Durability == DurabilitySync {
+		return nil
+	}
+	return f.file.Sync()
+}
+
+// close 将缓冲区中的日志写入文件并执行fsync，然后关闭日志文件并释放日志文件锁，锁文件本身不会被删除
+func (f *logFile) close() error {
+	var err error
+	if f.file != nil {
+		err = f.sync()
+		if e := f.file.Close(); err == nil {
+			err = e
+		}
+		f.setFile(nil)
+	}
+	if f.lock != nil {
+		_ = f.lock.Close()
+		f.lock = nil
+	}
+	return err
+}
+
+// flushByInterval 每隔interval调用一次flush，stop被关闭时退出
+func flushByInterval(interval time.Duration, stop <-chan struct{}, flush func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			flush()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// timedSlice 判断是否需要定时切片
+func (c Config) timedSlice() bool {
+	return c.MaxLogTime != 0 || len(c.SliceWhen) != 0 || c.Schedule != nil
+}
+
+// sliceByTime 按MaxLogTime、SliceWhen与Schedule定时调用rotate，stop被关闭时退出
+func sliceByTime(current Config, stop <-chan struct{}, rotate func()) {
+	clock := current.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	var last time.Time
+	for {
+		from := clock.Now()
+		if from.Before(last) {
+			from = last
+		}
+		last = nextSlice(current, from)
+		timer := clock.NewTimer(last.Sub(clock.Now()))
+		select {
+		case <-timer.C():
+			rotate()
+		case <-stop:
+			timer.Stop()
+			return
+		}
+	}
+}
@@ -0,0 +1,335 @@
+package logger
+
+import (
+	"os"
+	"strings"
+	"sync"
+)
+
+// FileSink 独立的日志文件输出，拥有各自的路径、切片配置、等级掩码与记录器名称筛选，
+// 通过Printer挂载到Logger（挂载到Logger后对其派生Logger同样生效），或通过AttachGlobal对全部Logger生效，与全局日志文件互不影响
+//
+// e.g.
+//
+//	errSink, _ := logger.NewFileSink(logger.Config{Name: "error.log"}, logger.LevelAtLeast(logger.LevelError))
+//	accessSink, _ := logger.NewFileSink(logger.Config{Name: "access.log", MaxSize: 64 << 20}, logger.LevelDefault, "http")
+//	sinks := logger.NewFileSinkGroup(errSink, accessSink).AttachGlobal()
+//	defer sinks.Close()
+type FileSink struct {
+	mu        sync.Mutex
+	out       logFile
+	levels    LogLevel
+	loggers   []string
+	formatter Formatter
+	printer   LogPrinter
+	attached  []*Logger
+	global    bool
+	stop      chan struct{}
+	closed    bool
+}
+
+// NewFileSink 以config打开一个日志文件输出，config.Name不能为空，其余未设置的配置项使用默认值；
+// CaptureStderr、StderrLogger、StderrLevel与ReopenOnSignal仅对全局日志生效，需要重新打开文件时调用Reopen。
+// levels为写入的日志等级掩码，loggers为记录器名称筛选，规则与SetLevelFor相同（"http"与"http.*"匹配http及其下级名称），为空时不筛选
+func NewFileSink(config Config, levels LogLevel, loggers ...string) (*FileSink, error) {
+	if config.Name == "" {
+		return nil, &GlobLogError{Op: GlobLogOpOpen, Path: config.Name, Err: os.ErrInvalid}
+	}
+	current := mergeConfig(config)
+	s := &FileSink{levels: levels, stop: make(chan struct{})}
+	for _, pattern := range loggers {
+		s.loggers = append(s.loggers, normalizeLevelPattern(pattern))
+	}
+	if err := s.out.open(&current); err != nil {
+		return nil, err
+	}
+	s.printer = s.print
+	if current.timedSlice() {
+		go sliceByTime(current, s.stop, s.rotate)
+	}
+	if current.Durability == DurabilityBuffered {
+		go flushByInterval(current.FlushInterval, s.stop, s.flush)
+	}
+	return s, nil
+}
+
+// SetFormatter 设置写入文件时使用的Formatter，为nil时依次使用全局文件Formatter与Logger的Formatter
+func (s *FileSink) SetFormatter(f Formatter) *FileSink {
+	s.mu.Lock()
+	s.formatter = f
+	s.mu.Unlock()
+	return s
+}
+
+// Name 返回日志文件路径（使用LockPerPID时可能带有进程号）
+func (s *FileSink) Name() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.out.config.Name
+}
+
+// Printer 返回写入该文件的Printer，可通过Logger.AddPrinter挂载，多次调用返回同一个Printer
+func (s *FileSink) Printer() LogPrinter {
+	return s.printer
+}
+
+// Attach 将Printer挂载到loggers，Close时会自动卸载
+func (s *FileSink) Attach(loggers ...*Logger) *FileSink {
+	for _, l := range loggers {
+		l.AddPrinter(s.printer)
+	}
+	s.mu.Lock()
+	s.attached = append(s.attached, loggers...)
+	s.mu.Unlock()
+	return s
+}
+
+// AttachGlobal 通过AddGlobalPrinter将Printer挂载到全部Logger（包括之后创建的Logger），由记录器名称筛选决定写入哪些Logger的日志，
+// Close时会自动卸载
+//
+// e.g. 将所有Logger的错误写入error.log
+//
+//	errSink, _ := logger.NewFileSink(logger.Config{Name: "error.log"}, logger.LevelAtLeast(logger.LevelError))
+//	errSink.AttachGlobal()
+func (s *FileSink) AttachGlobal() *FileSink {
+	s.mu.Lock()
+	attach := !s.global
+	s.global = true
+	s.mu.Unlock()
+	if attach {
+		AddGlobalPrinter(s.printer)
+	}
+	return s
+}
+
+// wants 判断日志是否需要写入该文件
+func (s *FileSink) wants(dump *LoggInfo) bool {
+	if dump.Level&s.levels == 0 {
+		return false
+	}
+	if len(s.loggers) == 0 {
+		return true
+	}
+	name := ""
+	if dump.from != nil {
+		name = dump.from.Name
+	}
+	for _, p := range s.loggers {
+		if p == "" || name == p || strings.HasPrefix(name, p+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FileSink) print(dump *LoggInfo) {
+	if !s.wants(dump) {
+		return
+	}
+	l := dump.from
+	if l == nil {
+		l = RootLogger
+	}
+	s.mu.Lock()
+	f := s.formatter
+	s.mu.Unlock()
+	if f == nil {
+		if f = GetFileFormatter(); f == nil {
+			f = l.GetFormatter()
+		}
+	}
+	buf := getBuffer()
+	defer putBuffer(buf)
+	*buf = append(appendFormat(*buf, f, l, dump, false), '\n')
+
+	s.mu.Lock()
+	var e error
+	if !s.closed {
+		e = s.out.write(dump.Level, *buf)
+	}
+	s.mu.Unlock()
+	if e != nil {
+		RootLogger.Error(WithContent("FileSink write error:", e.Error()))
+	}
+}
+
+func (s *FileSink) rotate() {
+	s.mu.Lock()
+	if !s.closed {
+		s.out.rotate()
+	}
+	s.mu.Unlock()
+}
+
+func (s *FileSink) flush() {
+	s.mu.Lock()
+	if !s.closed {
+		_ = s.out.flush()
+	}
+	s.mu.Unlock()
+}
+
+// Sync 将缓冲区中的日志写入文件并执行fsync
+func (s *FileSink) Sync() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	return s.out.sync()
+}
+
+// Reopen 以追加方式重新打开日志文件并再次写入Desc首行描述，用于配合logrotate等外部工具；打开失败时继续使用原文件
+func (s *FileSink) Reopen() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	file, err := s.out.reopen()
+	if err != nil {
+		return err
+	}
+	s.out.replace(file)
+	return nil
+}
+
+// Close 从Attach挂载的Logger上卸载Printer并等待其异步队列输出完成（AttachGlobal时卸载全局Printer并等待全部Logger），
+// 然后将缓冲区中的日志写入文件并关闭，之后的日志不再写入
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	attached, global := s.attached, s.global
+	s.attached, s.global = nil, false
+	s.mu.Unlock()
+	detach(s.printer, attached, global)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	close(s.stop)
+	return s.out.close()
+}
+
+// detach 等待异步队列输出完成后从attached上卸载printer，global为true时同时卸载全局Printer
+func detach(printer LogPrinter, attached []*Logger, global bool) {
+	if global {
+		Flush()
+		RemoveGlobalPrinter(printer)
+	}
+	for _, l := range attached {
+		l.Flush()
+		l.RemovePrinter(printer)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// FileSinkGroup Functions
+
+// FileSinkGroup 一组FileSink，作为一个Printer挂载并统一重新打开与关闭
+type FileSinkGroup struct {
+	mu       sync.RWMutex
+	sinks    []*FileSink
+	printer  LogPrinter
+	attached []*Logger
+	global   bool
+}
+
+// NewFileSinkGroup 创建包含sinks的FileSinkGroup
+func NewFileSinkGroup(sinks ...*FileSink) *FileSinkGroup {
+	g := &FileSinkGroup{sinks: sinks}
+	g.printer = g.print
+	return g
+}
+
+// Add 向组内添加FileSink，对已挂载的Logger立即生效
+func (g *FileSinkGroup) Add(sinks ...*FileSink) *FileSinkGroup {
+	g.mu.Lock()
+	g.sinks = append(g.sinks, sinks...)
+	g.mu.Unlock()
+	return g
+}
+
+// Sinks 返回组内的全部FileSink
+func (g *FileSinkGroup) Sinks() []*FileSink {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return append([]*FileSink(nil), g.sinks...)
+}
+
+// Printer 返回依次写入组内全部文件的Printer，多次调用返回同一个Printer
+func (g *FileSinkGroup) Printer() LogPrinter {
+	return g.printer
+}
+
+// Attach 将Printer挂载到loggers，Close时会自动卸载
+func (g *FileSinkGroup) Attach(loggers ...*Logger) *FileSinkGroup {
+	for _, l := range loggers {
+		l.AddPrinter(g.printer)
+	}
+	g.mu.Lock()
+	g.attached = append(g.attached, loggers...)
+	g.mu.Unlock()
+	return g
+}
+
+// AttachGlobal 通过AddGlobalPrinter将Printer挂载到全部Logger（包括之后创建的Logger），Close时会自动卸载
+func (g *FileSinkGroup) AttachGlobal() *FileSinkGroup {
+	g.mu.Lock()
+	attach := !g.global
+	g.global = true
+	g.mu.Unlock()
+	if attach {
+		AddGlobalPrinter(g.printer)
+	}
+	return g
+}
+
+func (g *FileSinkGroup) print(dump *LoggInfo) {
+	g.mu.RLock()
+	sinks := g.sinks
+	g.mu.RUnlock()
+	for _, s := range sinks {
+		s.print(dump)
+	}
+}
+
+// Reopen 重新打开组内全部文件，返回遇到的第一个错误
+func (g *FileSinkGroup) Reopen() error {
+	var first error
+	for _, s := range g.Sinks() {
+		if err := s.Reopen(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Sync 将组内全部文件缓冲区中的日志写入文件并执行fsync，返回遇到的第一个错误
+func (g *FileSinkGroup) Sync() error {
+	var first error
+	for _, s := range g.Sinks() {
+		if err := s.Sync(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Close 卸载Printer并关闭组内全部文件，返回遇到的第一个错误
+func (g *FileSinkGroup) Close() error {
+	g.mu.Lock()
+	attached, global := g.attached, g.global
+	g.attached, g.global = nil, false
+	g.mu.Unlock()
+	detach(g.printer, attached, global)
+	var first error
+	for _, s := range g.Sinks() {
+		if err := s.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
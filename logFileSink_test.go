@@ -0,0 +1,44 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileSinkAttachGlobal(t *testing.T) {
+	dir := t.TempDir()
+	sink, err := NewFileSink(Config{Name: filepath.Join(dir, "error.log"), ArchiveDir: filepath.Join(dir, "logs")},
+		LevelAtLeast(LevelError), t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink.AttachGlobal()
+
+	// 挂载之后才创建的Logger同样会被路由
+	GetLogger(t.Name(), false).Error(WithContent("routed error"))
+	GetLogger(t.Name(), false).Named("db").Fatal(WithContent("routed fatal"))
+	GetLogger(t.Name(), false).Common(WithContent("below level"))
+	GetLogger(t.Name()+"Other", false).Error(WithContent("other logger"))
+	if err = sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	GetLogger(t.Name(), false).Error(WithContent("after close"))
+
+	data, err := os.ReadFile(sink.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(data)
+	for _, want := range []string{"routed error", "routed fatal"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in:\n%s", want, got)
+		}
+	}
+	for _, unwanted := range []string{"below level", "other logger", "after close"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("unexpected %q in:\n%s", unwanted, got)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotationRedirectsStderr(t *testing.T) {
+	if saved, err := dupStderr(); err != nil {
+		t.Skip(err)
+	} else {
+		_ = saved.Close()
+	}
+	dir := useGlobLog(t, Config{Durability: DurabilityWrite, MaxSize: 1 << 10})
+	globMu.Lock()
+	err := redirectStderr(GlobalFileHandler)
+	globMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := GetLogger(t.Name(), false)
+	for i := 0; i < 50; i++ {
+		l.Common(WithContent("fill", i))
+	}
+	_, _ = os.Stderr.WriteString("stderr-after-size-rotation\n")
+	globMu.Lock()
+	rotateGlob()
+	globMu.Unlock()
+	_, _ = os.Stderr.WriteString("stderr-after-timed-rotation\n")
+
+	current := filepath.Join(dir, "glob.log")
+	if n := countLines(t, filepath.Join(dir, "logs"), "stderr-after-size-rotation"); n != 1 {
+		t.Fatalf("stderr written before timed rotation found %d times in archives, want 1", n)
+	}
+	data, err := os.ReadFile(current)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "stderr-after-timed-rotation") {
+		t.Fatalf("stderr not redirected to the current log file:\n%s", data)
+	}
+}
+
+func TestOpenIgnoresArchiveDirUntilNeeded(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "not-a-dir")
+	if err := os.WriteFile(blocker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := mergeConfig(Config{Name: filepath.Join(dir, "app.log"), ArchiveDir: blocker})
+
+	var f logFile
+	if err := f.open(&config); err != nil {
+		t.Fatalf("open without a previous log file: %v", err)
+	}
+	_ = f.close()
+
+	err := f.open(&config)
+	if err == nil {
+		_ = f.close()
+		t.Fatal("open succeeded although the previous log file cannot be archived")
+	}
+	if err.Op != GlobLogOpMkdir {
+		t.Fatalf("Op = %s, want %s", err.Op, GlobLogOpMkdir)
+	}
+}
+
+func TestLockWaitDoesNotBlockLogging(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "locked.log")
+	other, err := tryLockFile(name, false)
+	if err == errLockUnsupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	if saved, err := dupStderr(); err == nil {
+		defer func() { _ = restoreStderr(saved) }()
+	}
+	defer resetGlobLog()
+
+	initDone := make(chan error, 1)
+	go func() {
+		initDone <- InitGlobLogWithConfigE(Config{Name: name, ArchiveDir: filepath.Join(dir, "logs"), Lock: LockWait})
+	}()
+	l := GetLogger(t.Name(), false)
+	within(t, 2*time.Second, func() {
+		for i := 0; i < 10; i++ {
+			l.Common(WithContent("while waiting for the lock", i))
+			time.Sleep(5 * time.Millisecond)
+		}
+	})
+	select {
+	case err := <-initDone:
+		t.Fatalf("init returned while the lock was held: %v", err)
+	default:
+	}
+
+	_ = other.Close()
+	select {
+	case err := <-initDone:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("init did not finish after the lock was released")
+	}
+}
+
+func TestCloseGlobLog(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "closed.log")
+	if saved, err := dupStderr(); err == nil {
+		defer func() { _ = restoreStderr(saved) }()
+	}
+	defer resetGlobLog()
+
+	config := Config{Name: name, ArchiveDir: filepath.Join(dir, "logs"), Lock: LockFail, Durability: DurabilityBuffered, FlushInterval: time.Hour}
+	if err := InitGlobLogWithConfigE(config); err != nil {
+		t.Fatal(err)
+	}
+	l := GetLogger(t.Name(), false)
+	l.Common(WithContent("buffered-marker"))
+	if n := countLines(t, dir, "buffered-marker"); n != 0 {
+		t.Fatalf("buffered line already written %d times", n)
+	}
+	if err := CloseGlobLog(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, dir, "buffered-marker"); n != 1 {
+		t.Fatalf("buffered line written %d times after close, want 1", n)
+	}
+	if EnableGlobLog || GlobalFileHandler != nil {
+		t.Fatal("glob log still enabled after close")
+	}
+	l.Common(WithContent("after-close-marker"))
+	if n := countLines(t, dir, "after-close-marker"); n != 0 {
+		t.Fatalf("line written %d times after close", n)
+	}
+
+	other, err := tryLockFile(name, false)
+	if err == errLockUnsupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatalf("lock not released: %v", err)
+	}
+	_ = other.Close()
+	if err := InitGlobLogWithConfigE(config); err != nil {
+		t.Fatalf("init after close: %v", err)
+	}
+}
+
+func TestRotateReportsOpenFailure(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	config := mergeConfig(Config{Name: filepath.Join(sub, "app.log"), Durability: DurabilityWrite})
+	// 归档前删除日志所在目录，使归档与重新打开都失败
+	config.OldLogPath = func(info os.FileInfo) string {
+		_ = os.RemoveAll(sub)
+		return filepath.Join(dir, "archived.log")
+	}
+	var f logFile
+	if err := f.open(&config); err != nil {
+		t.Fatal(err)
+	}
+	defer f.close()
+	if err := f.write(LevelCommon, []byte("before\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	f.rotate()
+	err := f.write(LevelCommon, []byte("after\n"))
+	if ge, ok := err.(*GlobLogError); !ok || ge.Op != GlobLogOpOpen {
+		t.Fatalf("write after failed rotation = %v, want an open error", err)
+	}
+	if err = f.write(LevelCommon, []byte("again\n")); err != nil {
+		t.Fatalf("error reported twice: %v", err)
+	}
+}
+
+func TestReopenGlobLogAfterExternalRename(t *testing.T) {
+	dir := useGlobLog(t, Config{Durability: DurabilityWrite})
+	name := filepath.Join(dir, "glob.log")
+	moved := filepath.Join(dir, "glob.log.1")
+	l := GetLogger(t.Name(), false)
+	l.Common(WithContent("before-rename-marker"))
+	if err := os.Rename(name, moved); err != nil {
+		t.Fatal(err)
+	}
+	l.Common(WithContent("still-old-file-marker"))
+	if err := ReopenGlobLog(); err != nil {
+		t.Fatal(err)
+	}
+	l.Common(WithContent("after-reopen-marker"))
+
+	for _, tt := range []struct {
+		file, marker string
+		want         int
+	}{
+		{moved, "before-rename-marker", 1},
+		{moved, "still-old-file-marker", 1},
+		{moved, "after-reopen-marker", 0},
+		{name, "after-reopen-marker", 1},
+		{name, "before-rename-marker", 0},
+	} {
+		data, err := os.ReadFile(tt.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(string(data), tt.marker); n != tt.want {
+			t.Errorf("%s contains %s %d times, want %d", filepath.Base(tt.file), tt.marker, n, tt.want)
+		}
+	}
+}
+
+func TestReopenOnSignalReportsFailure(t *testing.T) {
+	dir := useGlobLog(t, Config{Durability: DurabilityWrite})
+	reported := make(chan string, 4)
+	collect := func(info *LoggInfo) {
+		if info.Level == LevelError {
+			reported <- info.Info.GetRawString()
+		}
+	}
+	RootLogger.AddPrinter(collect)
+	defer RootLogger.RemovePrinter(collect)
+	// 日志所在目录被删除后无法重新打开
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	ch, stop := make(chan os.Signal, 1), make(chan struct{})
+	defer close(stop)
+	go reopenGlobOnSignal(ch, stop)
+	ch <- os.Interrupt
+	select {
+	case msg := <-reported:
+		if !strings.Contains(msg, "ReopenGlobLog failed") {
+			t.Fatalf("reported %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("reopen failure not reported through RootLogger")
+	}
+}
+
+func TestLockPerPIDArchivesStaleFiles(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "app.log")
+	held, err := tryLockFile(name, false)
+	if err == errLockUnsupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Close()
+	// 已退出的进程留下的文件与仍在运行的进程持有的文件
+	stale, running := filepath.Join(dir, "app.999991.log"), filepath.Join(dir, "app.999992.log")
+	for _, f := range []string{stale, stale + ".lock", running} {
+		if err := os.WriteFile(f, []byte("stale-marker\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other, err := tryLockFile(running, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	config := mergeConfig(Config{Name: name, ArchiveDir: filepath.Join(dir, "logs"), Lock: LockPerPID})
+	lock, gerr := lockLogFile(&config)
+	if gerr != nil {
+		t.Fatal(gerr)
+	}
+	defer lock.Close()
+	archiveMu.Lock() // 等待后台归档维护结束
+	archiveMu.Unlock()
+
+	if want := filepath.Join(dir, "app."+strconv.Itoa(os.Getpid())+".log"); config.Name != want {
+		t.Fatalf("Name = %s, want %s", config.Name, want)
+	}
+	for _, tt := range []struct {
+		file   string
+		exists bool
+	}{
+		{stale, false},
+		{stale + ".lock", false},
+		{running, true},
+		{running + ".lock", true},
+	} {
+		if _, err := os.Stat(tt.file); (err == nil) != tt.exists {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(tt.file), err == nil, tt.exists)
+		}
+	}
+	if n := countLines(t, filepath.Join(dir, "logs"), "stale-marker"); n != 1 {
+		t.Fatalf("stale log archived %d times, want 1", n)
+	}
+}
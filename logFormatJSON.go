@@ -0,0 +1,164 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fexli/logger/logcolor"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// JSONFormatter 以JSON Lines格式输出日志，每条日志为一个扁平的JSON对象：
+//
+//	{"time":"2006-01-02T15:04:05.999+08:00","level":"COMMON","logger":"sys","file":"main.go","line":7,"func":"main.main","msg":"hello","key":"value"}
+//
+// 结构化字段作为顶层键输出，与保留键同名的字段会被加上"fields."前缀，同名的字段（如先后通过With添加）只输出最后一个；
+// 时间戳为0的日志不输出time
+type JSONFormatter struct{}
+
+var jsonReservedKeys = map[string]bool{
+	"time": true, "level": true, "logger": true, "file": true, "line": true, "func": true, "cur": true, "msg": true,
+	"trace_id": true, "span_id": true,
+}
+
+func (f JSONFormatter) Format(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx {
+	return logcolor.New().WithText(string(f.AppendFormat(make([]byte, 0, 256), l, dump, false)))
+}
+
+// AppendFormat 与Format输出相同，但直接追加到b，不输出颜色
+func (JSONFormatter) AppendFormat(b []byte, l *Logger, dump *LoggInfo, _ bool) []byte {
+	b = append(b, '{')
+	if dump.Ts != 0 {
+		b = append(b, `"time":`...)
+		b = append(b, '"')
+		b = dump.Time().AppendFormat(b, time.RFC3339Nano)
+		b = append(b, '"')
+		b = append(b, ',')
+	}
+	b = append(b, `"level":`...)
+	b = appendJSONString(b, dump.Level.String())
+	b = append(b, `,"logger":`...)
+	b = appendJSONString(b, l.Name)
+	if cur := dump.Caller(); cur != nil {
+		b = append(b, `,"file":`...)
+		b = appendJSONString(b, cur.FileName)
+		b = append(b, `,"line":`...)
+		b = strconv.AppendInt(b, int64(cur.Line), 10)
+		b = append(b, `,"func":`...)
+		b = appendJSONString(b, cur.Function)
+	}
+	if dump.MemCur != "" {
+		b = append(b, `,"cur":`...)
+		b = appendJSONString(b, dump.MemCur)
+	}
+	if dump.TraceID != "" {
+		b = append(b, `,"trace_id":`...)
+		b = appendJSONString(b, dump.TraceID)
+	}
+	if dump.SpanID != "" {
+		b = append(b, `,"span_id":`...)
+		b = appendJSONString(b, dump.SpanID)
+	}
+	b = append(b, `,"msg":`...)
+	b = appendJSONString(b, dump.Info.GetRawString())
+	for i, f := range dump.Fields {
+		if shadowedField(dump.Fields, i) {
+			continue
+		}
+		key := f.Key
+		if jsonReservedKeys[key] {
+			key = "fields." + key
+		}
+		b = append(b, ',')
+		b = appendJSONString(b, key)
+		b = append(b, ':')
+		b = f.appendJSON(b)
+	}
+	b = append(b, '}')
+	return b
+}
+
+// shadowedField 判断fields[i]之后是否有同名的字段，JSON对象中重复的键会被许多解析器丢弃或拒绝
+func shadowedField(fields []Field, i int) bool {
+	for _, f := range fields[i+1:] {
+		if f.Key == fields[i].Key {
+			return true
+		}
+	}
+	return false
+}
+
+// appendJSONValue 将任意值编码为JSON追加到b，error与无法编码的值按字符串输出
+func appendJSONValue(b []byte, v interface{}) []byte {
+	switch val := v.(type) {
+	case nil:
+		return append(b, "null"...)
+	case string:
+		return appendJSONString(b, val)
+	case error:
+		return appendJSONString(b, val.Error())
+	case json.Marshaler:
+	case fmt.Stringer:
+		return appendJSONString(b, val.String())
+	}
+	buf := &bytes.Buffer{}
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return appendJSONString(b, fmt.Sprintf("%+v", v))
+	}
+	return append(b, bytes.TrimRight(buf.Bytes(), "\n")...)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendJSONString 将字符串编码为JSON字符串追加到b，不转义HTML字符
+func appendJSONString(b []byte, s string) []byte {
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b = append(b, s[start:i]...)
+			b = append(b, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
@@ -0,0 +1,69 @@
+package logger
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// formatJSON 输出一条日志并以JSONFormatter格式化，返回输出的行与解析后的对象
+func formatJSON(t *testing.T, l *Logger, opts ...LogComponent) (string, map[string]interface{}) {
+	t.Helper()
+	l.Common(opts...)
+	line := JSONFormatter{}.Format(l, l.GetLatestLog()).GetRawString()
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		t.Fatalf("invalid JSON %q: %v", line, err)
+	}
+	return line, obj
+}
+
+func TestJSONFormatterEscaping(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	tests := []struct {
+		name, msg, want string
+	}{
+		{"plain", "hello world", "hello world"},
+		{"quotes and backslash", `say "hi" \ bye`, `say "hi" \ bye`},
+		{"whitespace", "a\nb\rc\td", "a\nb\rc\td"},
+		{"control", "bell\x07", "bell\x07"},
+		{"html is not escaped", "<a href='x'>&</a>", "<a href='x'>&</a>"},
+		{"line separators", "a\u2028b\u2029c", "a\u2028b\u2029c"},
+		{"invalid utf-8", "bad\xffbyte", "bad\ufffdbyte"},
+		{"unicode", "日志 ✓", "日志 ✓"},
+	}
+	for _, tt := range tests {
+		line, obj := formatJSON(t, l, WithContent(tt.msg))
+		if strings.ContainsAny(line, "\n\r\u2028\u2029") {
+			t.Errorf("%s: unescaped line break in %q", tt.name, line)
+		}
+		if obj["msg"] != tt.want {
+			t.Errorf("%s: msg = %q, want %q", tt.name, obj["msg"], tt.want)
+		}
+	}
+}
+
+func TestJSONFormatterFieldKeys(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	tests := []struct {
+		name string
+		kvs  []interface{}
+		want map[string]interface{}
+	}{
+		{"reserved keys are prefixed", []interface{}{"msg", "user msg", "level", "custom", "time", 1},
+			map[string]interface{}{"msg": "content", "fields.msg": "user msg", "level": "COMMON", "fields.level": "custom", "fields.time": float64(1)}},
+		{"escaped key", []interface{}{"a\"b", "v"}, map[string]interface{}{"a\"b": "v"}},
+		{"duplicate keys keep the last value", []interface{}{"a", 1, "b", 2, "a", 3}, map[string]interface{}{"a": float64(3), "b": float64(2)}},
+	}
+	for _, tt := range tests {
+		line, obj := formatJSON(t, l, WithContent("content"), WithKVs(tt.kvs...))
+		for k, v := range tt.want {
+			if obj[k] != v {
+				t.Errorf("%s: %s = %v, want %v (%s)", tt.name, k, obj[k], v, line)
+			}
+		}
+		if n := strings.Count(line, `"a":`); n > 1 {
+			t.Errorf("%s: key a written %d times: %s", tt.name, n, line)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package logger
+
+import (
+	"fmt"
+	"github.com/fexli/logger/logcolor"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LogfmtFormatter 以logfmt格式输出日志，适用于lnav、Loki等工具：
+//
+//	ts=2006-01-02T15:04:05.999+08:00 level=COMMON logger=sys caller=main.go:7 msg="hello world" key=value
+//
+// 结构化字段按添加顺序追加在msg之后，与保留键同名的字段会被加上"fields."前缀；时间戳为0的日志不输出ts
+type LogfmtFormatter struct{}
+
+var logfmtReservedKeys = map[string]bool{
+	"ts": true, "level": true, "logger": true, "caller": true, "cur": true, "msg": true,
+	"trace_id": true, "span_id": true,
+}
+
+func (f LogfmtFormatter) Format(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx {
+	return logcolor.New().WithText(string(f.AppendFormat(make([]byte, 0, 256), l, dump, false)))
+}
+
+// AppendFormat 与Format输出相同，但直接追加到b，不输出颜色
+func (LogfmtFormatter) AppendFormat(b []byte, l *Logger, dump *LoggInfo, _ bool) []byte {
+	if dump.Ts != 0 {
+		b = append(b, "ts="...)
+		b = dump.Time().AppendFormat(b, time.RFC3339Nano)
+		b = append(b, ' ')
+	}
+	b = append(b, "level="...)
+	b = append(b, dump.Level.String()...)
+	b = append(b, " logger="...)
+	b = appendLogfmtValue(b, l.Name)
+	if cur := dump.Caller(); cur != nil {
+		b = append(b, " caller="...)
+		b = appendLogfmtValue(b, cur.FileName+":"+strconv.Itoa(cur.Line))
+	}
+	if dump.MemCur != "" {
+		b = append(b, " cur="...)
+		b = appendLogfmtValue(b, dump.MemCur)
+	}
+	if dump.TraceID != "" {
+		b = append(b, " trace_id="...)
+		b = appendLogfmtValue(b, dump.TraceID)
+	}
+	if dump.SpanID != "" {
+		b = append(b, " span_id="...)
+		b = appendLogfmtValue(b, dump.SpanID)
+	}
+	b = append(b, " msg="...)
+	b = appendLogfmtValue(b, dump.Info.GetRawString())
+	for _, f := range dump.Fields {
+		key := f.Key
+		if logfmtReservedKeys[key] {
+			key = "fields." + key
+		}
+		b = appendLogfmtField(b, key, f)
+	}
+	return b
+}
+
+// appendLogfmtField 追加一个字段，嵌套对象展开为"key.sub=value"
+func appendLogfmtField(b []byte, key string, f Field) []byte {
+	if f.Type == FieldObject {
+		for _, sub := range f.Fields() {
+			b = appendLogfmtField(b, key+"."+sub.Key, sub)
+		}
+		return b
+	}
+	b = append(b, ' ')
+	b = appendLogfmtKey(b, key)
+	b = append(b, '=')
+	if f.Type == FieldAny {
+		return appendLogfmtValue(b, logfmtString(f.Iface))
+	}
+	return appendLogfmtValue(b, f.textValue())
+}
+
+// logfmtString 将字段值转换为字符串，复杂类型以JSON表示
+func logfmtString(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return val
+	case []byte:
+		return string(val)
+	case error:
+		return val.Error()
+	case bool:
+		return strconv.FormatBool(val)
+	case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8:
+		return fmt.Sprintf("%d", val)
+	case float32:
+		return strconv.FormatFloat(float64(val), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(val, 'g', -1, 64)
+	case time.Time:
+		return val.Format(time.RFC3339Nano)
+	case time.Duration:
+		return val.String()
+	case fmt.Stringer:
+		return val.String()
+	}
+	return string(appendJSONValue(nil, v))
+}
+
+// appendLogfmtKey 追加logfmt键，键中的空白、'='、'"'与控制字符会被替换为'_'
+func appendLogfmtKey(b []byte, key string) []byte {
+	if key == "" {
+		return append(b, '_')
+	}
+	for _, r := range key {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			b = append(b, '_')
+		} else {
+			b = append(b, string(r)...)
+		}
+	}
+	return b
+}
+
+// appendLogfmtValue 追加logfmt值，值为空或包含空白、'='、'"'、'\'及控制字符时加引号并转义
+func appendLogfmtValue(b []byte, value string) []byte {
+	if !logfmtNeedsQuote(value) {
+		return append(b, value...)
+	}
+	b = append(b, '"')
+	for _, r := range value {
+		switch r {
+		case '"', '\\':
+			b = append(b, '\\', byte(r))
+		case '\n':
+			b = append(b, '\\', 'n')
+		case '\r':
+			b = append(b, '\\', 'r')
+		case '\t':
+			b = append(b, '\\', 't')
+		default:
+			if r < ' ' || r == utf8.RuneError || !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+				b = append(b, fmt.Sprintf("\\u%04x", r)...)
+			} else {
+				b = append(b, string(r)...)
+			}
+		}
+	}
+	return append(b, '"')
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || unicode.IsSpace(r) || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
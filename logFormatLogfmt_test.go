@@ -0,0 +1,76 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+// formatLogfmt 输出一条日志并以LogfmtFormatter格式化
+func formatLogfmt(l *Logger, opts ...LogComponent) string {
+	l.Common(opts...)
+	return LogfmtFormatter{}.Format(l, l.GetLatestLog()).GetRawString()
+}
+
+func TestLogfmtFormatterValues(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"plain", "value", `k=value`},
+		{"empty", "", `k=""`},
+		{"space", "a b", `k="a b"`},
+		{"equals", "a=b", `k="a=b"`},
+		{"quotes and backslash", `say "hi" \`, `k="say \"hi\" \\"`},
+		{"whitespace", "a\nb\rc\td", `k="a\nb\rc\td"`},
+		{"control", "bell\x07", `k="bell\u0007"`},
+		{"invalid utf-8", "bad\xff", `k="bad\ufffd"`},
+		{"unicode", "日志", `k=日志`},
+		{"int", 42, `k=42`},
+		{"bool", true, `k=true`},
+		{"nil", nil, `k=null`},
+		{"map as JSON", map[string]int{"a": 1}, `k="{\"a\":1}"`},
+	}
+	for _, tt := range tests {
+		line := formatLogfmt(l, WithContent("m"), WithKVs("k", tt.value))
+		if !strings.HasSuffix(line, " msg=m "+tt.want) {
+			t.Errorf("%s: got %q, want suffix %q", tt.name, line, tt.want)
+		}
+	}
+}
+
+func TestLogfmtFormatterKeys(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	tests := []struct {
+		name, key, want string
+	}{
+		{"plain", "user.id", "user.id=1"},
+		{"space", "user id", "user_id=1"},
+		{"equals and quote", `a="b`, "a__b=1"},
+		{"control", "a\nb", "a_b=1"},
+		{"empty", "", "_=1"},
+		{"reserved msg", "msg", "fields.msg=1"},
+		{"reserved level", "level", "fields.level=1"},
+	}
+	for _, tt := range tests {
+		line := formatLogfmt(l, WithContent("m"), WithKVs(tt.key, 1))
+		if !strings.HasSuffix(line, " msg=m "+tt.want) {
+			t.Errorf("%s: got %q, want suffix %q", tt.name, line, tt.want)
+		}
+	}
+}
+
+func TestLogfmtFormatterHeader(t *testing.T) {
+	l := GetLogger("logfmt test", false)
+	line := formatLogfmt(l, WithContent("hello world"))
+	if !strings.HasPrefix(line, "ts=") {
+		t.Fatalf("missing ts in %q", line)
+	}
+	if want := ` level=COMMON logger="logfmt test" `; !strings.Contains(line, want) {
+		t.Fatalf("%q does not contain %q", line, want)
+	}
+	if !strings.HasSuffix(line, ` msg="hello world"`) {
+		t.Fatalf("unexpected msg in %q", line)
+	}
+}
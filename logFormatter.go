@@ -0,0 +1,265 @@
+package logger
+
+import (
+	"github.com/fexli/logger/logcolor"
+	"sync"
+	"unicode/utf8"
+)
+
+// Formatter 将一条日志信息与其所属Logger的元信息（名称、是否显示调用位置等）格式化为LogTextCtx，
+// 格式化结果用于控制台输出，未通过SetFileFormatter单独设置时也用于全局日志文件
+type Formatter interface {
+	Format(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx
+}
+
+// AppendFormatter 可以将日志直接格式化到字节缓冲区的Formatter，控制台与日志文件输出时优先使用AppendFormat，
+// 避免构建LogTextCtx；colored为false时不应输出颜色控制序列。内置的Formatter均实现了该接口
+type AppendFormatter interface {
+	Formatter
+	AppendFormat(b []byte, l *Logger, dump *LoggInfo, colored bool) []byte
+}
+
+// FormatterFunc 以函数形式实现Formatter
+type FormatterFunc func(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx
+
+func (f FormatterFunc) Format(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx {
+	return f(l, dump)
+}
+
+// TextFormatter 默认的文本格式：[15:04:05.000][memcur]("file",in func line N)<name>[LEVL]message [trace=4bf92f35 span=00f067aa]，
+// 日志带有TraceID时才输出trace后缀
+type TextFormatter struct{}
+
+var (
+	formatterMu     sync.RWMutex
+	globalFormatter Formatter = TextFormatter{}
+	fileFormatter   Formatter = nil
+)
+
+// SetFormatter 同时设置控制台与全局日志文件的默认Formatter，未单独设置Formatter的Logger均使用该Formatter，传入nil时恢复为TextFormatter
+func SetFormatter(f Formatter) {
+	if f == nil {
+		f = TextFormatter{}
+	}
+	formatterMu.Lock()
+	globalFormatter = f
+	fileFormatter = nil
+	formatterMu.Unlock()
+}
+
+// SetConsoleFormatter 仅设置控制台的默认Formatter，传入nil时恢复为TextFormatter
+func SetConsoleFormatter(f Formatter) {
+	if f == nil {
+		f = TextFormatter{}
+	}
+	formatterMu.Lock()
+	globalFormatter = f
+	formatterMu.Unlock()
+}
+
+// SetFileFormatter 单独设置全局日志文件的Formatter，传入nil时日志文件与控制台使用相同的Formatter
+//
+// e.g.
+//
+//	logger.SetFileFormatter(logger.JSONFormatter{}) // 控制台保持彩色文本，日志文件输出JSON Lines
+func SetFileFormatter(f Formatter) {
+	formatterMu.Lock()
+	fileFormatter = f
+	formatterMu.Unlock()
+}
+
+// GetFormatter 获取控制台的默认Formatter
+func GetFormatter() Formatter {
+	formatterMu.RLock()
+	defer formatterMu.RUnlock()
+	return globalFormatter
+}
+
+// GetFileFormatter 获取全局日志文件单独设置的Formatter，未设置时返回nil
+func GetFileFormatter() Formatter {
+	formatterMu.RLock()
+	defer formatterMu.RUnlock()
+	return fileFormatter
+}
+
+// appendFormat 使用f将日志格式化后追加到b
+func appendFormat(b []byte, f Formatter, l *Logger, dump *LoggInfo, colored bool) []byte {
+	if af, ok := f.(AppendFormatter); ok {
+		return af.AppendFormat(b, l, dump, colored)
+	}
+	return appendLogText(b, f.Format(l, dump), colored)
+}
+
+// appendLogText 将text追加到b，colored为false时不输出颜色控制序列
+func appendLogText(b []byte, text *logcolor.LogTextCtx, colored bool) []byte {
+	if colored {
+		return text.AppendBytes(b, text.Color)
+	}
+	return text.AppendRawBytes(b)
+}
+
+// appendInnerText 将作为子节点的text追加到b，与其被Then到无颜色的根节点后输出的结果相同
+func appendInnerText(b []byte, text *logcolor.LogTextCtx, colored bool) []byte {
+	if colored {
+		return text.AppendBytes(b, nil)
+	}
+	return text.AppendRawBytes(b)
+}
+
+// appendKV 追加一个结构化字段：换行缩进后按10个字符宽度左对齐键名
+func appendKV(b []byte, key string, value string) []byte {
+	b = append(b, "\n\t- "...)
+	b = append(b, key...)
+	for n := utf8.RuneCountInString(key); n < 10; n++ {
+		b = append(b, ' ')
+	}
+	b = append(b, "= "...)
+	return append(b, value...)
+}
+
+// AppendFormat 与Format输出相同，但直接追加到b
+func (TextFormatter) AppendFormat(b []byte, l *Logger, dump *LoggInfo, colored bool) []byte {
+	if dump.Ts != 0 {
+		if colored {
+			b = TimeColor.AppendStart(b)
+		}
+		b = append(b, '[')
+		b = dump.Time().AppendFormat(b, "15:04:05.000")
+		b = append(b, ']')
+		if colored {
+			b = TimeColor.AppendEnd(b)
+		}
+	}
+	if len(dump.MemCur) > 0 {
+		if colored {
+			b = MemCurColor.AppendStart(b)
+		}
+		b = append(b, '[')
+		b = append(b, dump.MemCur...)
+		b = append(b, ']')
+		if colored {
+			b = MemCurColor.AppendEnd(b)
+		}
+	}
+	if l.ShowCur() || (dump.Level&LevelShowcur) > 0 {
+		if colored {
+			b = StackColor.AppendStart(b)
+		}
+		b = dump.callerOrEmpty().appendTo(b)
+		if colored {
+			b = StackColor.AppendEnd(b)
+		}
+	}
+	b = append(b, '<')
+	b = append(b, l.Name...)
+	b = append(b, '>', '[')
+	b = appendInnerText(b, LogPrefix[dump.Level], colored)
+	b = append(b, ']')
+	b = appendInnerText(b, dump.Info, colored)
+	if dump.TraceID != "" {
+		if colored {
+			b = TraceColor.AppendStart(b)
+		}
+		b = appendTraceSuffix(b, dump)
+		if colored {
+			b = TraceColor.AppendEnd(b)
+		}
+	}
+	for _, f := range dump.Fields {
+		b = appendKV(b, f.Key, f.textValue())
+	}
+	return b
+}
+
+// appendTraceSuffix 追加简短的trace后缀：" [trace=前8位 span=前8位]"
+func appendTraceSuffix(b []byte, dump *LoggInfo) []byte {
+	b = append(b, " [trace="...)
+	b = append(b, shortTraceID(dump.TraceID)...)
+	if dump.SpanID != "" {
+		b = append(b, " span="...)
+		b = append(b, shortTraceID(dump.SpanID)...)
+	}
+	return append(b, ']')
+}
+
+func shortTraceID(id string) string {
+	if len(id) > 8 {
+		return id[:8]
+	}
+	return id
+}
+
+func (TextFormatter) Format(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx {
+	ent := logcolor.New()
+	if dump.Ts != 0 {
+		ent.Then(
+			logcolor.New().WithText(
+				"[" + dump.Time().Format("15:04:05.000") + "]",
+			).WithColor(TimeColor),
+		)
+	}
+	if len(dump.MemCur) > 0 {
+		ent.Then(
+			logcolor.New().WithText(
+				"[" + dump.MemCur + "]",
+			).WithColor(MemCurColor),
+		)
+	}
+	if l.ShowCur() || (dump.Level&LevelShowcur) > 0 {
+		ent.Then(
+			logcolor.New().WithText(
+				dump.callerOrEmpty().Format(),
+			).WithColor(StackColor),
+		)
+	}
+
+	ent.Then(
+		logcolor.New().WithText(
+			"<" + l.Name + ">",
+		),
+	)
+
+	ent.Then(logcolor.New().WithText("["))
+	ent.Then(LogPrefix[dump.Level])
+	ent.Then(logcolor.New().WithText("]"))
+
+	ent.Then(dump.Info)
+	if dump.TraceID != "" {
+		ent.Then(logcolor.New().WithText(string(appendTraceSuffix(nil, dump))).WithColor(TraceColor))
+	}
+	if len(dump.Fields) > 0 {
+		kvs := make([]byte, 0, 32*len(dump.Fields))
+		for _, f := range dump.Fields {
+			kvs = appendKV(kvs, f.Key, f.textValue())
+		}
+		ent.Then(logcolor.New().WithText(string(kvs)))
+	}
+	return ent
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Logger Formatter Functions
+
+// SetFormatter 为当前Logger单独设置控制台与日志文件的Formatter（日志文件单独设置的Formatter优先），传入nil时恢复使用全局默认Formatter
+func (l *Logger) SetFormatter(f Formatter) *Logger {
+	l.mu.Lock()
+	l.formatter = f
+	l.mu.Unlock()
+	return l
+}
+
+// GetFormatter 获取当前Logger实际使用的Formatter
+func (l *Logger) GetFormatter() Formatter {
+	l.mu.RLock()
+	f := l.formatter
+	l.mu.RUnlock()
+	if f == nil {
+		return GetFormatter()
+	}
+	return f
+}
+
+// ShowCur 当前Logger是否总是显示日志调用位置
+func (l *Logger) ShowCur() bool {
+	return l.logShowCur
+}
@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fexli/logger/logcolor"
+)
+
+func TestTextFormatter(t *testing.T) {
+	ts := float64(time.Date(2024, 3, 1, 9, 8, 7, 654e6, time.Local).UnixNano()) / 1e9
+	cur := &CurInfo{Function: "main.run", Line: 42, FilePath: "/src/main.go", FileName: "main.go"}
+	tests := []struct {
+		name    string
+		showCur bool
+		dump    LoggInfo
+		want    string
+	}{
+		{"message only", false, LoggInfo{Level: LevelCommon, Info: logcolor.New().WithText("hello")},
+			"<text>[INFO]hello"},
+		{"timestamp", false, LoggInfo{Ts: ts, Level: LevelWarning, Info: logcolor.New().WithText("hello")},
+			"[09:08:07.654]<text>[WARN]hello"},
+		{"memcur", false, LoggInfo{Ts: ts, Level: LevelNotice, MemCur: "12MB", Info: logcolor.New().WithText("hello")},
+			"[09:08:07.654][12MB]<text>[NOTE]hello"},
+		{"caller", true, LoggInfo{Level: LevelSystem, Cur: cur, Info: logcolor.New().WithText("hello")},
+			`("main.go",in main.run line 42)<text.showcur>[SYST]hello`},
+		{"caller hidden", false, LoggInfo{Level: LevelSystem, Cur: cur, Info: logcolor.New().WithText("hello")},
+			"<text>[SYST]hello"},
+	}
+	for _, tt := range tests {
+		l := GetLogger("text", false)
+		if tt.showCur {
+			l = GetLogger("text.showcur", true)
+		}
+		if got := (TextFormatter{}).Format(l, &tt.dump).GetRawString(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLoggerFormatter(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	if _, ok := l.GetFormatter().(TextFormatter); !ok {
+		t.Fatalf("default formatter is %T", l.GetFormatter())
+	}
+	custom := FormatterFunc(func(l *Logger, dump *LoggInfo) *logcolor.LogTextCtx {
+		return logcolor.New().WithText(l.Name + ":" + dump.Info.GetRawString())
+	})
+	l.SetFormatter(custom)
+	if got := l.GetFormatter().Format(l, &LoggInfo{Info: logcolor.New().WithText("hi")}).GetRawString(); got != t.Name()+":hi" {
+		t.Fatalf("custom formatter produced %q", got)
+	}
+	l.SetFormatter(nil)
+	if _, ok := l.GetFormatter().(TextFormatter); !ok {
+		t.Fatalf("formatter after reset is %T", l.GetFormatter())
+	}
+}
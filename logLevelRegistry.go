@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const levelExplicit = 1 << 8
+
+var (
+	levelMu    sync.RWMutex
+	levelRules = make(map[string]LogLevel)
+	// levelGen 任意等级规则或Logger等级变化时递增，用于使各Logger缓存的生效等级失效
+	levelGen uint64 = 1
+)
+
+// SetLevelFor 按点分名称为一组Logger设置日志等级，规则作用于该名称及其全部下级名称，
+// 更长（更具体）的规则覆盖较短的规则，对已存在与之后创建的Logger（含Named派生的Logger）均立即生效。
+// pattern可以写作"db"或"db.*"，""与"*"表示根规则，即匹配全部Logger。
+// 通过SetLogLevel显式设置了等级的Logger不受规则影响。
+//
+// e.g.
+//
+//	logger.SetLevelFor("*", logger.LevelAtLeast(logger.LevelWarning)) // 默认只输出Warning及以上
+//	logger.SetLevelFor("db.*", logger.LevelDefault)                    // db及其下级输出全部等级
+func SetLevelFor(pattern string, level LogLevel) {
+	pattern = normalizeLevelPattern(pattern)
+	levelMu.Lock()
+	levelRules[pattern] = level
+	levelMu.Unlock()
+	atomic.AddUint64(&levelGen, 1)
+}
+
+// RemoveLevelFor 移除SetLevelFor注册的等级规则
+func RemoveLevelFor(pattern string) {
+	pattern = normalizeLevelPattern(pattern)
+	levelMu.Lock()
+	delete(levelRules, pattern)
+	levelMu.Unlock()
+	atomic.AddUint64(&levelGen, 1)
+}
+
+// ResetLevelRules 移除全部等级规则
+func ResetLevelRules() {
+	levelMu.Lock()
+	levelRules = make(map[string]LogLevel)
+	levelMu.Unlock()
+	atomic.AddUint64(&levelGen, 1)
+}
+
+// LevelFor 按等级规则解析指定名称的日志等级，不考虑Logger显式设置的等级
+func LevelFor(name string) LogLevel {
+	if level, _, ok := matchLevelRule(name); ok {
+		return level
+	}
+	return LevelDefault
+}
+
+func ruleSource(pattern string) string {
+	if pattern == "" {
+		return "rule:*"
+	}
+	return "rule:" + pattern
+}
+
+func normalizeLevelPattern(pattern string) string {
+	if pattern == "*" {
+		return ""
+	}
+	return strings.TrimSuffix(pattern, ".*")
+}
+
+// matchLevelRule 查找与name匹配的最长等级规则
+func matchLevelRule(name string) (level LogLevel, pattern string, ok bool) {
+	levelMu.RLock()
+	defer levelMu.RUnlock()
+	if len(levelRules) == 0 {
+		return 0, "", false
+	}
+	for p := name; ; {
+		if level, ok = levelRules[p]; ok {
+			return level, p, true
+		}
+		if p == "" {
+			return 0, "", false
+		}
+		if idx := strings.LastIndexByte(p, '.'); idx >= 0 {
+			p = p[:idx]
+		} else {
+			p = ""
+		}
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Logger Level Functions
+
+// effectiveLevel 返回当前Logger生效的日志等级，结果按levelGen缓存
+func (l *Logger) effectiveLevel() LogLevel {
+	gen := atomic.LoadUint64(&levelGen)
+	if cached := atomic.LoadUint64(&l.resolved); cached>>8 == gen {
+		return LogLevel(cached)
+	}
+	level, _ := l.resolveLevel()
+	atomic.StoreUint64(&l.resolved, gen<<8|uint64(level))
+	return level
+}
+
+// resolveLevel 按 显式等级 > 等级规则 > 父Logger > LevelDefault 的顺序解析日志等级，并返回等级来源；
+// 派生Logger仅采用比父Logger名称更具体的规则，其余情况继承父Logger的等级
+func (l *Logger) resolveLevel() (LogLevel, string) {
+	if v := atomic.LoadUint32(&l.level); v&levelExplicit != 0 {
+		return LogLevel(v), "explicit"
+	}
+	level, pattern, ok := matchLevelRule(l.Name)
+	if l.parent != nil && (!ok || len(pattern) <= len(l.parent.Name)) {
+		return l.parent.effectiveLevel(), "parent"
+	}
+	if ok {
+		return level, ruleSource(pattern)
+	}
+	return LevelDefault, "default"
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Level Tree
+
+// LevelNode 等级树中的一个节点
+type LevelNode struct {
+	// 点分名称，根规则为""
+	Name string `json:"name"`
+	// 生效的日志等级
+	Level LogLevel `json:"level"`
+	// 等级来源：explicit（显式设置）、rule:<pattern>（等级规则）、parent（继承父Logger）、default（默认）
+	Source string `json:"source"`
+	// 是否存在该名称的Logger，为false时该节点仅为一条等级规则
+	Logger bool `json:"logger"`
+}
+
+// LevelTree 返回全局pool中全部Logger与全部等级规则的生效等级，按名称排序
+func LevelTree() []LevelNode {
+	nodes := make(map[string]LevelNode)
+	levelMu.RLock()
+	for pattern := range levelRules {
+		nodes[pattern] = LevelNode{Name: pattern}
+	}
+	levelMu.RUnlock()
+	poolMu.Lock()
+	loggers := make([]*Logger, 0, len(pool))
+	for _, l := range pool {
+		loggers = append(loggers, l)
+	}
+	poolMu.Unlock()
+
+	for name := range nodes {
+		level, pattern, _ := matchLevelRule(name)
+		nodes[name] = LevelNode{Name: name, Level: level, Source: ruleSource(pattern)}
+	}
+	for _, l := range loggers {
+		level, source := l.resolveLevel()
+		nodes[l.Name] = LevelNode{Name: l.Name, Level: level, Source: source, Logger: true}
+	}
+
+	result := make([]LevelNode, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// PrintLevelTree 将LevelTree以缩进的树形写入w
+//
+// e.g.
+//
+//	logger.PrintLevelTree(os.Stdout)
+//	// * = FATAL|NOTICE|ERROR|WARNING (rule:*)
+//	//   db = ALL (rule:db)
+//	//     db.pool = ALL (rule:db) [logger]
+//	//   sys = ALL (explicit) [logger]
+func PrintLevelTree(w io.Writer) {
+	nodes := LevelTree()
+	hasRoot := len(nodes) > 0 && nodes[0].Name == ""
+	for _, node := range nodes {
+		name, depth := node.Name, 0
+		if name == "" {
+			name = "*"
+		} else {
+			depth = strings.Count(name, ".")
+			if hasRoot {
+				depth++
+			}
+		}
+		mark := ""
+		if node.Logger {
+			mark = " [logger]"
+		}
+		_, _ = fmt.Fprintf(w, "%s%s = %s (%s)%s\n", strings.Repeat("  ", depth), name, node.Level, node.Source, mark)
+	}
+}
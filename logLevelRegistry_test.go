@@ -0,0 +1,99 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestLevelForRules(t *testing.T) {
+	defer ResetLevelRules()
+	SetLevelFor("*", LevelAtLeast(LevelWarning))
+	SetLevelFor("db.*", LevelDefault)
+	SetLevelFor("db.pool.slow", LevelError)
+	tests := []struct {
+		name string
+		want LogLevel
+	}{
+		{"", LevelAtLeast(LevelWarning)},
+		{"app", LevelAtLeast(LevelWarning)},
+		{"dbx", LevelAtLeast(LevelWarning)},
+		{"db", LevelDefault},
+		{"db.pool", LevelDefault},
+		{"db.pool.slow", LevelError},
+		{"db.pool.slow.query", LevelError},
+		{"db.pool.slowest", LevelDefault},
+	}
+	for _, tt := range tests {
+		if got := LevelFor(tt.name); got != tt.want {
+			t.Errorf("LevelFor(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	RemoveLevelFor("db")
+	if got := LevelFor("db.pool"); got != LevelAtLeast(LevelWarning) {
+		t.Errorf("after RemoveLevelFor: LevelFor(db.pool) = %v", got)
+	}
+}
+
+func TestNamedLevelInheritance(t *testing.T) {
+	defer ResetLevelRules()
+	parent := GetLogger(t.Name(), false)
+	child := parent.Named("worker")
+	grandchild := child.Named("job")
+
+	SetLevelFor(t.Name(), LevelAtLeast(LevelWarning))
+	for _, l := range []*Logger{parent, child, grandchild} {
+		if got := l.GetLogLevel(); got != LevelAtLeast(LevelWarning) {
+			t.Errorf("%s: level %v from parent rule", l.Name, got)
+		}
+	}
+
+	parent.SetLogLevel(LevelError)
+	defer parent.ResetLogLevel()
+	if got := grandchild.GetLogLevel(); got != LevelError {
+		t.Errorf("grandchild did not inherit the explicit parent level: %v", got)
+	}
+	// 比父Logger名称更具体的规则优先于父Logger的等级
+	SetLevelFor(child.Name, LevelDefault)
+	if got := grandchild.GetLogLevel(); got != LevelDefault {
+		t.Errorf("grandchild ignored the more specific rule: %v", got)
+	}
+	grandchild.SetLogLevel(LevelFatal)
+	if got := grandchild.GetLogLevel(); got != LevelFatal {
+		t.Errorf("explicit level did not win: %v", got)
+	}
+}
+
+func TestLevelTree(t *testing.T) {
+	defer ResetLevelRules()
+	name := strings.ToLower(t.Name())
+	GetLogger(name, false)
+	l := GetLogger(name+".a", false)
+	SetLevelFor(name, LevelAtLeast(LevelError))
+	SetLevelFor(name+".b.c", LevelDefault)
+	want := []LevelNode{
+		{Name: name, Level: LevelAtLeast(LevelError), Source: "rule:" + name, Logger: true},
+		{Name: name + ".a", Level: LevelAtLeast(LevelError), Source: "rule:" + name, Logger: true},
+		{Name: name + ".b.c", Level: LevelDefault, Source: "rule:" + name + ".b.c"},
+	}
+	var got []LevelNode
+	for _, node := range LevelTree() {
+		if node.Name == name || strings.HasPrefix(node.Name, name+".") {
+			got = append(got, node)
+		}
+	}
+	if len(got) != len(want) {
+		t.Fatalf("LevelTree returned %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("node %d: %+v, want %+v", i, got[i], want[i])
+		}
+	}
+	l.SetLogLevel(LevelWarning)
+	defer l.ResetLogLevel()
+	var sb strings.Builder
+	PrintLevelTree(&sb)
+	if want := "  " + name + ".a = WARNING (explicit) [logger]\n"; !strings.Contains(sb.String(), want) {
+		t.Errorf("PrintLevelTree output missing %q:\n%s", want, sb.String())
+	}
+}
@@ -0,0 +1,66 @@
+package logger
+
+import "sync"
+
+// bufferPool 复用格式化日志所用的字节缓冲区
+var bufferPool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// maxPooledBuffer 超过该容量的缓冲区不放回bufferPool，避免偶发的超长日志长期占用内存
+const maxPooledBuffer = 64 << 10
+
+// getBuffer 从bufferPool中取出一个空的缓冲区，使用完毕后需通过putBuffer放回
+func getBuffer() *[]byte {
+	b := bufferPool.Get().(*[]byte)
+	*b = (*b)[:0]
+	return b
+}
+
+func putBuffer(b *[]byte) {
+	if cap(*b) <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+// optionsPool 复用解析日志组件所用的logOptions
+var optionsPool = sync.Pool{
+	New: func() interface{} {
+		return new(logOptions)
+	},
+}
+
+// maxPooledContent 超过该容量的Info不随logOptions放回optionsPool
+const maxPooledContent = 64
+
+// parseOption 从optionsPool中取出logOptions并依次应用opts，使用完毕后需通过releaseOption放回
+func parseOption(opts ...LogComponent) *logOptions {
+	dopts := optionsPool.Get().(*logOptions)
+	info, contexts := dopts.Info[:0], dopts.contexts[:0]
+	*dopts = defaultOptions()
+	dopts.Info, dopts.contexts = info, contexts
+	for _, opt := range opts {
+		opt.apply(dopts)
+	}
+	return dopts
+}
+
+// releaseOption 清除dopts对日志内容的引用并放回optionsPool，Fields已交给LoggInfo，不会被复用
+func releaseOption(dopts *logOptions) {
+	for i := range dopts.Info {
+		dopts.Info[i] = nil
+	}
+	if cap(dopts.Info) > maxPooledContent {
+		dopts.Info = nil
+	}
+	dopts.Info = dopts.Info[:0]
+	for i := range dopts.contexts {
+		dopts.contexts[i] = pendingContext{}
+	}
+	dopts.contexts = dopts.contexts[:0]
+	dopts.Fields = nil
+	optionsPool.Put(dopts)
+}
@@ -0,0 +1,35 @@
+package logger
+
+import "testing"
+
+// TestAllocBudgets 检查热路径的内存分配预算，控制台输出到io.Discard，历史记录保留最近1000条
+func TestAllocBudgets(t *testing.T) {
+	if raceEnabled {
+		t.Skip("allocation counts are not stable under the race detector")
+	}
+	text := GetLogger(t.Name()+".text", false).SetRetention(Retention{MaxEntries: 1000})
+	json := GetLogger(t.Name()+".json", false).SetRetention(Retention{MaxEntries: 1000}).SetFormatter(JSONFormatter{})
+	disabled := GetLogger(t.Name()+".disabled", false)
+	disabled.SetLogLevel(LevelAtLeast(LevelWarning))
+	tests := []struct {
+		name   string
+		budget float64
+		fn     func()
+	}{
+		// WithContent的参数切片与组件、LoggInfo、LogTextCtx及其文本
+		{"Common/WithContent", 5, func() {
+			text.Common(WithContent("request finished", 200))
+		}},
+		{"Common/WithContent/JSON", 5, func() {
+			json.Common(WithContent("request finished", 200))
+		}},
+		{"Disabled/Debug", 0, func() {
+			disabled.Debug()
+		}},
+	}
+	for _, tt := range tests {
+		if n := testing.AllocsPerRun(1000, tt.fn); n > tt.budget {
+			t.Errorf("%s: %.1f allocs/op, budget %.0f", tt.name, n, tt.budget)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package logger
+
+import "time"
+
+// Retention 日志历史记录保留策略，各项为零值时表示不限制
+//
+// e.g.
+//
+//	logger.RootLogger.SetRetention(logger.Retention{MaxEntries: 1000, MaxAge: time.Hour})
+type Retention struct {
+	// 最大保留条数
+	MaxEntries int
+	// 最大保留字节数（按日志内容的无色文本长度计算）
+	MaxBytes int
+	// 最大保留时长
+	MaxAge time.Duration
+	// 日志被淘汰时的回调，在Logger锁外按淘汰顺序调用
+	OnEvict func(info *LoggInfo)
+	// 同时记录当前等级未启用（不输出）的日志，启用后未启用等级的日志同样需要构建内容与获取调用位置
+	KeepDisabled bool
+}
+
+var defaultRetention = Retention{}
+
+// SetDefaultRetention 设置之后由GetLogger创建的Logger所使用的默认保留策略，已存在的Logger不受影响
+func SetDefaultRetention(r Retention) {
+	poolMu.Lock()
+	defaultRetention = r
+	poolMu.Unlock()
+}
+
+// logRing 基于环形缓冲区的日志历史记录，非并发安全，由Logger.mu保护
+type logRing struct {
+	buf    []*LoggInfo
+	sizes  []int
+	head   int
+	n      int
+	bytes  int
+	policy Retention
+}
+
+func newLogRing(policy Retention) *logRing {
+	return &logRing{policy: policy}
+}
+
+func (r *logRing) at(i int) int {
+	return (r.head + i) % len(r.buf)
+}
+
+func (r *logRing) grow() {
+	newCap := len(r.buf) * 2
+	if newCap == 0 {
+		newCap = 16
+	}
+	if r.policy.MaxEntries > 0 && newCap > r.policy.MaxEntries {
+		newCap = r.policy.MaxEntries
+	}
+	buf := make([]*LoggInfo, newCap)
+	sizes := make([]int, newCap)
+	for i := 0; i < r.n; i++ {
+		buf[i] = r.buf[r.at(i)]
+		sizes[i] = r.sizes[r.at(i)]
+	}
+	r.buf, r.sizes, r.head = buf, sizes, 0
+}
+
+// popFront 移除并返回最早的一条记录
+func (r *logRing) popFront() *LoggInfo {
+	dump := r.buf[r.head]
+	r.bytes -= r.sizes[r.head]
+	r.buf[r.head] = nil
+	r.sizes[r.head] = 0
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+	return dump
+}
+
+// evict 淘汰最早的一条记录，设置了OnEvict时将其追加到evicted
+func (r *logRing) evict(evicted []*LoggInfo) []*LoggInfo {
+	dump := r.popFront()
+	if r.policy.OnEvict == nil {
+		return evicted
+	}
+	return append(evicted, dump)
+}
+
+// push 追加一条记录，返回因保留策略被淘汰的记录
+func (r *logRing) push(dump *LoggInfo) (evicted []*LoggInfo) {
+	if r.policy.MaxEntries > 0 {
+		for r.n >= r.policy.MaxEntries {
+			evicted = r.evict(evicted)
+		}
+	}
+	if r.n == len(r.buf) {
+		r.grow()
+	}
+	size := 0
+	if r.policy.MaxBytes > 0 {
+		size = len(dump.Info.GetRawString())
+	}
+	idx := r.at(r.n)
+	r.buf[idx] = dump
+	r.sizes[idx] = size
+	r.n++
+	r.bytes += size
+	return r.trim(dump.Ts, evicted)
+}
+
+// trim 按字节数与时长淘汰最早的记录，now为当前时间戳（秒）
+func (r *logRing) trim(now float64, evicted []*LoggInfo) []*LoggInfo {
+	if r.policy.MaxBytes > 0 {
+		for r.n > 1 && r.bytes > r.policy.MaxBytes {
+			evicted = r.evict(evicted)
+		}
+	}
+	if r.policy.MaxAge > 0 {
+		deadline := now - r.policy.MaxAge.Seconds()
+		for r.n > 0 && r.buf[r.head].Ts < deadline {
+			evicted = r.evict(evicted)
+		}
+	}
+	return evicted
+}
+
+// setPolicy 更新保留策略并立即按新策略淘汰记录
+func (r *logRing) setPolicy(policy Retention, now float64) (evicted []*LoggInfo) {
+	items := r.snapshot()
+	r.clear()
+	r.policy = policy
+	for _, dump := range items {
+		evicted = append(evicted, r.push(dump)...)
+	}
+	return r.trim(now, evicted)
+}
+
+func (r *logRing) clear() {
+	r.buf, r.sizes = nil, nil
+	r.head, r.n, r.bytes = 0, 0, 0
+}
+
+func (r *logRing) latest() *LoggInfo {
+	if r.n == 0 {
+		return nil
+	}
+	return r.buf[r.at(r.n-1)]
+}
+
+// snapshot 按时间顺序返回当前保留的全部记录
+func (r *logRing) snapshot() []*LoggInfo {
+	result := make([]*LoggInfo, r.n)
+	for i := 0; i < r.n; i++ {
+		result[i] = r.buf[r.at(i)]
+	}
+	return result
+}
+
+// notifyEvict 在锁外依次将被淘汰的记录交给回调
+func notifyEvict(onEvict func(info *LoggInfo), evicted []*LoggInfo) {
+	if onEvict == nil {
+		return
+	}
+	for _, dump := range evicted {
+		onEvict(dump)
+	}
+}
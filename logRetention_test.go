@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fexli/logger/logcolor"
+)
+
+// ringEntry 以时间戳ts（秒）与内容text构建一条日志
+func ringEntry(ts float64, text string) *LoggInfo {
+	return &LoggInfo{Ts: ts, Level: LevelCommon, Info: logcolor.New().WithText(text)}
+}
+
+// ringTexts 返回记录的内容
+func ringTexts(entries []*LoggInfo) string {
+	texts := make([]string, 0, len(entries))
+	for _, e := range entries {
+		texts = append(texts, e.Info.GetRawString())
+	}
+	return strings.Join(texts, ",")
+}
+
+func TestLogRingRetention(t *testing.T) {
+	tests := []struct {
+		name        string
+		policy      Retention
+		entries     []*LoggInfo
+		wantKept    string
+		wantEvicted string
+	}{
+		{"unlimited", Retention{}, []*LoggInfo{ringEntry(1, "a"), ringEntry(2, "b")}, "a,b", ""},
+		{"max entries", Retention{MaxEntries: 2},
+			[]*LoggInfo{ringEntry(1, "a"), ringEntry(2, "b"), ringEntry(3, "c"), ringEntry(4, "d")}, "c,d", "a,b"},
+		{"max bytes", Retention{MaxBytes: 5},
+			[]*LoggInfo{ringEntry(1, "aa"), ringEntry(2, "bb"), ringEntry(3, "cc")}, "bb,cc", "aa"},
+		{"max bytes keeps the latest entry", Retention{MaxBytes: 3},
+			[]*LoggInfo{ringEntry(1, "a"), ringEntry(2, "too long")}, "too long", "a"},
+		{"max age", Retention{MaxAge: 10 * time.Second},
+			[]*LoggInfo{ringEntry(100, "a"), ringEntry(105, "b"), ringEntry(112, "c")}, "b,c", "a"},
+		{"combined", Retention{MaxEntries: 3, MaxAge: time.Minute},
+			[]*LoggInfo{ringEntry(0, "a"), ringEntry(10, "b"), ringEntry(20, "c"), ringEntry(30, "d"), ringEntry(90, "e")}, "d,e", "a,b,c"},
+	}
+	for _, tt := range tests {
+		tt.policy.OnEvict = func(*LoggInfo) {}
+		r := newLogRing(tt.policy)
+		var evicted []*LoggInfo
+		for _, e := range tt.entries {
+			evicted = append(evicted, r.push(e)...)
+		}
+		if got := ringTexts(r.snapshot()); got != tt.wantKept {
+			t.Errorf("%s: kept %q, want %q", tt.name, got, tt.wantKept)
+		}
+		if got := ringTexts(evicted); got != tt.wantEvicted {
+			t.Errorf("%s: evicted %q, want %q", tt.name, got, tt.wantEvicted)
+		}
+	}
+}
+
+func TestLogRingWrapsAround(t *testing.T) {
+	r := newLogRing(Retention{MaxEntries: 20})
+	for i := 0; i < 100; i++ {
+		r.push(ringEntry(float64(i), strconv.Itoa(i)))
+	}
+	kept := r.snapshot()
+	if len(kept) != 20 || kept[0].Info.GetRawString() != "80" || r.latest().Info.GetRawString() != "99" {
+		t.Fatalf("kept %s", ringTexts(kept))
+	}
+	// 收紧策略时立即淘汰
+	evicted := r.setPolicy(Retention{MaxEntries: 5, OnEvict: func(*LoggInfo) {}}, 99)
+	if len(evicted) != 15 || ringTexts(r.snapshot()) != "95,96,97,98,99" {
+		t.Fatalf("after setPolicy kept %s, evicted %d", ringTexts(r.snapshot()), len(evicted))
+	}
+}
+
+func TestRetentionOnEvict(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	var evicted []string
+	l.SetRetention(Retention{MaxEntries: 2, OnEvict: func(info *LoggInfo) {
+		evicted = append(evicted, info.Info.GetRawString())
+	}})
+	for _, msg := range []string{"first", "second", "third", "fourth"} {
+		l.Common(WithContent(msg))
+	}
+	if got := strings.Join(evicted, ","); got != "first,second" {
+		t.Fatalf("evicted %q", got)
+	}
+	if logs := l.GetLogs(0, LevelDefault, -1); len(logs) != 2 || logs[1].Info.GetRawString() != "fourth" {
+		t.Fatalf("history holds %d entries", len(logs))
+	}
+}
@@ -0,0 +1,364 @@
+package logger
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule 日志切片计划，Next返回严格晚于t的下一次切片时间
+//
+// 内置的计划均按所在时区的墙上时间计算，跨越夏令时切换时不会因按24小时累加而产生偏移；
+// 夏令时开始时被跳过的时刻不会触发，夏令时结束时重复出现的时刻只触发一次
+type Schedule interface {
+	Next(t time.Time) time.Time
+}
+
+// ScheduleFunc 以函数实现Schedule
+type ScheduleFunc func(t time.Time) time.Time
+
+func (f ScheduleFunc) Next(t time.Time) time.Time {
+	return f(t)
+}
+
+// ErrScheduleNeverFires Config.Schedule永远不会触发（Next返回零值），如"0 0 30 2 *"
+var ErrScheduleNeverFires = errors.New("logger: schedule never fires")
+
+// checkSchedule 检查current.Schedule能否触发，避免定时切片退化为按24小时切片
+func checkSchedule(current Config) *GlobLogError {
+	if current.Schedule == nil {
+		return nil
+	}
+	clock := current.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	if current.Schedule.Next(clock.Now()).IsZero() {
+		return &GlobLogError{Op: GlobLogOpSchedule, Path: current.Name, Err: ErrScheduleNeverFires}
+	}
+	return nil
+}
+
+// Clock 定时切片使用的时钟，默认为SystemClock，可在测试中替换为可控的时钟
+type Clock interface {
+	Now() time.Time
+	NewTimer(d time.Duration) Timer
+}
+
+// Timer Clock创建的定时器
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+// SystemClock 基于time包的系统时钟
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) NewTimer(d time.Duration) Timer {
+	return systemTimer{time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	t *time.Timer
+}
+
+func (t systemTimer) C() <-chan time.Time {
+	return t.t.C
+}
+
+func (t systemTimer) Stop() bool {
+	return t.t.Stop()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Cron Schedule
+
+// cronSchedule 以位图表示的cron计划，各位图的第n位表示取值n
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// dom与dow均不为"*"时，两者满足其一即可（与cron一致）
+	domStar, dowStar bool
+	loc              *time.Location
+}
+
+var cronDescriptors = map[string]string{
+	"@hourly":   "0 * * * *",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@weekly":   "0 0 * * 0",
+	"@monthly":  "0 0 1 * *",
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+}
+
+var (
+	cronMonthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	cronDowNames   = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+)
+
+// ParseCron 解析5段式cron表达式（分 时 日 月 周），支持"*"、列表"a,b"、范围"a-b"、步长"*/n"与"a-b/n"、
+// 月份与星期的英文缩写（JAN、MON等，周日可写作0或7）以及@hourly、@daily、@weekly、@monthly、@yearly，
+// 可选loc为计算所用的时区，默认为time.Local
+//
+// e.g.
+//
+//	schedule, err := logger.ParseCron("0 0 * * MON", time.UTC) // 每周一0点（UTC）
+func ParseCron(expr string, loc ...*time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if d, ok := cronDescriptors[strings.ToLower(expr)]; ok {
+		expr = d
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != 5 {
+		return nil, errors.New("logger: cron expression must have 5 fields: " + expr)
+	}
+	s := &cronSchedule{loc: scheduleLocation(loc)}
+	var err error
+	if s.minute, err = parseCronField(parts[0], 0, 59, nil); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseCronField(parts[1], 0, 23, nil); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseCronField(parts[2], 1, 31, nil); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseCronField(parts[3], 1, 12, cronMonthNames); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseCronField(parts[4], 0, 7, cronDowNames); err != nil {
+		return nil, err
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	s.domStar = parts[2] == "*" || parts[2] == "?"
+	s.dowStar = parts[4] == "*" || parts[4] == "?"
+	if !s.satisfiable() {
+		return nil, errors.New("logger: cron expression never fires: " + expr)
+	}
+	return s, nil
+}
+
+// cronMaxDays 各月份最多的天数
+var cronMaxDays = [13]int{0, 31, 29, 31, 30, 31, 30, 31, 31, 30, 31, 30, 31}
+
+// satisfiable 判断计划是否存在可以触发的日期：只限定日时，所选月份中必须有日期不超过该月的天数
+func (s *cronSchedule) satisfiable() bool {
+	if s.domStar || !s.dowStar {
+		return true
+	}
+	for m := 1; m <= 12; m++ {
+		if s.month&(1<<uint(m)) != 0 && s.dom&(1<<uint(cronMaxDays[m]+1)-1) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// MustParseCron 与ParseCron相同，表达式无效时panic
+func MustParseCron(expr string, loc ...*time.Location) Schedule {
+	s, err := ParseCron(expr, loc...)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseCronField 解析cron表达式的一段，返回取值位图
+func parseCronField(field string, lo, hi int, names map[string]int) (uint64, error) {
+	var bits uint64
+	for _, item := range strings.Split(field, ",") {
+		rng, step := item, 1
+		if idx := strings.IndexByte(item, '/'); idx >= 0 {
+			n, err := strconv.Atoi(item[idx+1:])
+			if err != nil || n <= 0 {
+				return 0, errors.New("logger: invalid cron step: " + item)
+			}
+			rng, step = item[:idx], n
+		}
+		start, end := lo, hi
+		if rng != "*" && rng != "?" {
+			bounds := strings.SplitN(rng, "-", 2)
+			var err error
+			if start, err = parseCronValue(bounds[0], names); err != nil {
+				return 0, err
+			}
+			end = start
+			if len(bounds) == 2 {
+				if end, err = parseCronValue(bounds[1], names); err != nil {
+					return 0, err
+				}
+			} else if step > 1 {
+				end = hi
+			}
+		}
+		if start < lo || end > hi || start > end {
+			return 0, errors.New("logger: cron value out of range: " + item)
+		}
+		for v := start; v <= end; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+func parseCronValue(s string, names map[string]int) (int, error) {
+	if v, ok := names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New("logger: invalid cron value: " + s)
+	}
+	return v, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := s.dom&(1<<uint(t.Day())) != 0
+	dowMatch := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	origin := t.In(s.loc)
+	t = origin.Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		switch {
+		case s.month&(1<<uint(t.Month())) == 0:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc)
+		case !s.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc)
+		case s.hour&(1<<uint(t.Hour())) == 0:
+			t = t.Add(time.Duration(60-t.Minute()) * time.Minute)
+		case s.minute&(1<<uint(t.Minute())) == 0:
+			t = t.Add(time.Minute)
+		case !wallAfter(t, origin):
+			// 夏令时结束时重复出现的墙上时间已经触发过
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// wallAfter 按墙上时间比较a是否晚于b
+func wallAfter(a, b time.Time) bool {
+	return wallClock(a).After(wallClock(b))
+}
+
+func wallClock(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+}
+
+func scheduleLocation(loc []*time.Location) *time.Location {
+	if len(loc) == 0 || loc[0] == nil {
+		return time.Local
+	}
+	return loc[0]
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Calendar Schedules
+
+// checkCalendarArg 检查日历计划的参数，超出[lo, hi]时panic，避免计划永远不触发而退化为按24小时切片
+func checkCalendarArg(fn, name string, v, lo, hi int) {
+	if v < lo || v > hi {
+		panic("logger: " + fn + ": " + name + " out of range [" + strconv.Itoa(lo) + ", " + strconv.Itoa(hi) + "]: " + strconv.Itoa(v))
+	}
+}
+
+// Hourly 每小时整点切片
+func Hourly(loc ...*time.Location) Schedule {
+	return &cronSchedule{minute: 1, hour: 1<<24 - 1, dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1<<7 - 1, domStar: true, dowStar: true, loc: scheduleLocation(loc)}
+}
+
+// Daily 每天hour:min切片，hour或min超出范围时panic
+func Daily(hour, min int, loc ...*time.Location) Schedule {
+	checkCalendarArg("Daily", "hour", hour, 0, 23)
+	checkCalendarArg("Daily", "min", min, 0, 59)
+	return &cronSchedule{minute: 1 << uint(min), hour: 1 << uint(hour), dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1<<7 - 1, domStar: true, dowStar: true, loc: scheduleLocation(loc)}
+}
+
+// Weekly 每周day的hour:min切片，参数超出范围时panic
+//
+// e.g.
+//
+//	logger.Weekly(time.Monday, 0, 0) // 每周一0点
+func Weekly(day time.Weekday, hour, min int, loc ...*time.Location) Schedule {
+	checkCalendarArg("Weekly", "day", int(day), 0, 6)
+	checkCalendarArg("Weekly", "hour", hour, 0, 23)
+	checkCalendarArg("Weekly", "min", min, 0, 59)
+	return &cronSchedule{minute: 1 << uint(min), hour: 1 << uint(hour), dom: 1<<32 - 2, month: 1<<13 - 2, dow: 1 << uint(day), domStar: true, loc: scheduleLocation(loc)}
+}
+
+// Monthly 每月day日的hour:min切片，没有该日期的月份不切片，参数超出范围时panic
+//
+// e.g.
+//
+//	logger.Monthly(1, 0, 0) // 每月1日0点
+func Monthly(day, hour, min int, loc ...*time.Location) Schedule {
+	checkCalendarArg("Monthly", "day", day, 1, 31)
+	checkCalendarArg("Monthly", "hour", hour, 0, 23)
+	checkCalendarArg("Monthly", "min", min, 0, 59)
+	return &cronSchedule{minute: 1 << uint(min), hour: 1 << uint(hour), dom: 1 << uint(day), month: 1<<13 - 2, dow: 1<<7 - 1, dowStar: true, loc: scheduleLocation(loc)}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Slice Timing
+
+// Next 返回各切片时刻中严格晚于t的最早一个，使SliceTime可以作为Schedule使用
+func (s SliceTime) Next(t time.Time) time.Time {
+	var next time.Time
+	for _, v := range s {
+		loc := v.TZone
+		if loc == nil {
+			loc = time.Local
+		}
+		lt := t.In(loc)
+		c := time.Date(lt.Year(), lt.Month(), lt.Day(), v.Hour, v.Min, v.Second, 0, loc)
+		if !c.After(t) {
+			c = time.Date(lt.Year(), lt.Month(), lt.Day()+1, v.Hour, v.Min, v.Second, 0, loc)
+		}
+		if next.IsZero() || c.Before(next) {
+			next = c
+		}
+	}
+	return next
+}
+
+// nextSlice 返回from之后的下一次定时切片时间：Schedule与SliceWhen取较早者，均未设置时为from+MaxLogTime
+func nextSlice(cfg Config, from time.Time) time.Time {
+	var next time.Time
+	for _, s := range []Schedule{cfg.Schedule, cfg.SliceWhen} {
+		if s == nil {
+			continue
+		}
+		if ss, ok := s.(SliceTime); ok && len(ss) == 0 {
+			continue
+		}
+		if c := s.Next(from); !c.IsZero() && (next.IsZero() || c.Before(next)) {
+			next = c
+		}
+	}
+	if next.IsZero() {
+		d := cfg.MaxLogTime
+		if d <= 0 {
+			d = time.Hour * 24
+		}
+		next = from.Add(d)
+	}
+	return next
+}
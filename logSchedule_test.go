@@ -0,0 +1,283 @@
+package logger
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return loc
+}
+
+func TestParseCron(t *testing.T) {
+	from := time.Date(2024, 1, 15, 10, 20, 30, 0, time.UTC) // 周一
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"* * * * *", time.Date(2024, 1, 15, 10, 21, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 1, 16, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC)},
+		{"30 2 * * MON,fri", time.Date(2024, 1, 19, 2, 30, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, 1, 21, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 MAR *", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2,3 *", time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 MON", time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC)},
+		// 日与周均不为"*"时满足其一即可
+		{"0 0 20 * MON", time.Date(2024, 1, 20, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		s, err := ParseCron(tt.expr, time.UTC)
+		if err != nil {
+			t.Errorf("ParseCron(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := s.Next(from); !got.Equal(tt.want) {
+			t.Errorf("ParseCron(%q).Next = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+
+	for _, expr := range []string{"", "* * * *", "* * * * * *", "60 * * * *", "* 24 * * *", "* * 0 * *",
+		"* * * 13 *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "x * * * *", "* * * FOO *",
+		"0 0 30 2 *", "0 0 31 4,6,9,11 *"} {
+		if _, err := ParseCron(expr); err == nil {
+			t.Errorf("ParseCron(%q) succeeded, want error", expr)
+		}
+	}
+}
+
+func TestScheduleAcrossDST(t *testing.T) {
+	ny := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name     string
+		schedule Schedule
+		from     time.Time
+		want     time.Time
+	}{
+		// 2024-03-10 02:00 EST跳至03:00 EDT
+		{"midnight before spring forward", Daily(0, 0, ny), time.Date(2024, 3, 10, 0, 0, 0, 0, ny), time.Date(2024, 3, 11, 0, 0, 0, 0, ny)},
+		{"skipped wall time", Daily(2, 30, ny), time.Date(2024, 3, 9, 12, 0, 0, 0, ny), time.Date(2024, 3, 11, 2, 30, 0, 0, ny)},
+		{"hourly over the gap", Hourly(ny), time.Date(2024, 3, 10, 1, 30, 0, 0, ny), time.Date(2024, 3, 10, 3, 0, 0, 0, ny)},
+		// 2024-11-03 02:00 EDT回到01:00 EST
+		{"repeated wall time fires once", Daily(1, 30, ny), time.Date(2024, 11, 3, 1, 30, 0, 0, ny), time.Date(2024, 11, 4, 1, 30, 0, 0, ny)},
+		{"midnight after fall back", Daily(0, 0, ny), time.Date(2024, 11, 3, 0, 0, 0, 0, ny), time.Date(2024, 11, 4, 0, 0, 0, 0, ny)},
+		{"cron in repeated hour", MustParseCron("0 1 * * *", ny), time.Date(2024, 11, 2, 12, 0, 0, 0, ny), time.Date(2024, 11, 3, 1, 0, 0, 0, ny)},
+	}
+	for _, tt := range tests {
+		got := tt.schedule.Next(tt.from)
+		if !got.Equal(tt.want) {
+			t.Errorf("%s: Next(%v) = %v, want %v", tt.name, tt.from, got, tt.want)
+		}
+		if got.In(ny).Hour() != tt.want.Hour() {
+			t.Errorf("%s: wall clock hour %d, want %d", tt.name, got.In(ny).Hour(), tt.want.Hour())
+		}
+	}
+
+	// 按墙上时间计算：夏令时开始当天只有23小时，结束当天有25小时
+	days := []struct {
+		day  time.Time
+		want time.Duration
+	}{
+		{time.Date(2024, 3, 9, 0, 0, 0, 0, ny), 24 * time.Hour},
+		{time.Date(2024, 3, 10, 0, 0, 0, 0, ny), 23 * time.Hour},
+		{time.Date(2024, 11, 3, 0, 0, 0, 0, ny), 25 * time.Hour},
+	}
+	for _, d := range days {
+		if got := Daily(0, 0, ny).Next(d.day).Sub(d.day); got != d.want {
+			t.Errorf("day %s lasted %v, want %v", d.day.Format("2006-01-02"), got, d.want)
+		}
+	}
+}
+
+func TestCalendarScheduleArguments(t *testing.T) {
+	loc := time.UTC
+	from := time.Date(2024, 1, 15, 10, 20, 0, 0, loc)
+	if got, want := Weekly(time.Sunday, 23, 59, loc).Next(from), time.Date(2024, 1, 21, 23, 59, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Weekly.Next = %v, want %v", got, want)
+	}
+	if got, want := Monthly(31, 0, 0, loc).Next(time.Date(2024, 2, 1, 0, 0, 0, 0, loc)), time.Date(2024, 3, 31, 0, 0, 0, 0, loc); !got.Equal(want) {
+		t.Errorf("Monthly(31).Next = %v, want %v", got, want)
+	}
+
+	invalid := map[string]func(){
+		"Daily hour":    func() { Daily(25, 0) },
+		"Daily min":     func() { Daily(0, 60) },
+		"Daily neg":     func() { Daily(-1, 0) },
+		"Weekly day":    func() { Weekly(time.Weekday(7), 0, 0) },
+		"Weekly hour":   func() { Weekly(time.Monday, 24, 0) },
+		"Monthly day 0": func() { Monthly(0, 0, 0) },
+		"Monthly day":   func() { Monthly(32, 0, 0) },
+		"Monthly min":   func() { Monthly(1, 0, 99) },
+	}
+	for name, f := range invalid {
+		func() {
+			defer func() {
+				r := recover()
+				if r == nil {
+					t.Errorf("%s: no panic", name)
+				} else if msg, _ := r.(string); !strings.Contains(msg, "out of range") {
+					t.Errorf("%s: unexpected panic %v", name, r)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestSliceTimeNext(t *testing.T) {
+	shanghai := mustLoadLocation(t, "Asia/Shanghai")
+	s := *NewSliceTime(6, 0, 0, time.UTC).And(18, 30, 0, time.UTC).And(9, 0, 0, shanghai) // 上海9点为UTC 1点
+	day := func(h, m int) time.Time { return time.Date(2024, 1, 15, h, m, 0, 0, time.UTC) }
+	tests := []struct{ from, want time.Time }{
+		{day(0, 0), day(1, 0)},
+		{day(1, 0), day(6, 0)},
+		{day(6, 0), day(18, 30)},
+		{day(18, 30), day(1, 0).AddDate(0, 0, 1)},
+		{day(23, 59), day(1, 0).AddDate(0, 0, 1)},
+	}
+	for _, tt := range tests {
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("Next(%v) = %v, want %v", tt.from, got, tt.want)
+		}
+	}
+
+	cfg := Config{SliceWhen: *NewSliceTime(18, 30, 0, time.UTC), Schedule: Hourly(time.UTC)}
+	if got := nextSlice(cfg, day(17, 10)); !got.Equal(day(18, 0)) {
+		t.Errorf("nextSlice picked %v, want the earlier Schedule time", got)
+	}
+	if got := nextSlice(Config{MaxLogTime: time.Hour}, day(17, 10)); !got.Equal(day(18, 10)) {
+		t.Errorf("nextSlice with MaxLogTime = %v", got)
+	}
+}
+
+// fakeClock 测试用的时钟，每次创建定时器时将其发送到timers
+type fakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers chan *fakeTimer
+}
+
+type fakeTimer struct {
+	at time.Time
+	c  chan time.Time
+}
+
+func newFakeClock(now time.Time) *fakeClock {
+	return &fakeClock{now: now, timers: make(chan *fakeTimer, 1)}
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) set(now time.Time) {
+	c.mu.Lock()
+	c.now = now
+	c.mu.Unlock()
+}
+
+func (c *fakeClock) NewTimer(d time.Duration) Timer {
+	t := &fakeTimer{at: c.Now().Add(d), c: make(chan time.Time, 1)}
+	c.timers <- t
+	return t
+}
+
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTimer) Stop() bool {
+	return true
+}
+
+// next 等待sliceByTime创建下一个定时器
+func (c *fakeClock) next(t *testing.T) *fakeTimer {
+	t.Helper()
+	select {
+	case timer := <-c.timers:
+		return timer
+	case <-time.After(2 * time.Second):
+		t.Fatal("sliceByTime did not arm a timer")
+		return nil
+	}
+}
+
+func TestSliceByTimeWithFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 15, 10, 20, 0, 0, time.UTC)
+	clock := newFakeClock(start)
+	rotated := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sliceByTime(Config{Schedule: Hourly(time.UTC), Clock: clock}, stop, func() { rotated <- struct{}{} })
+	}()
+
+	fire := func(timer *fakeTimer, now time.Time) {
+		clock.set(now)
+		timer.c <- now
+		select {
+		case <-rotated:
+		case <-time.After(2 * time.Second):
+			t.Fatal("rotate not called")
+		}
+	}
+
+	timer := clock.next(t)
+	if want := time.Date(2024, 1, 15, 11, 0, 0, 0, time.UTC); !timer.at.Equal(want) {
+		t.Fatalf("first slice at %v, want %v", timer.at, want)
+	}
+	// 定时器提前触发时不应在同一时刻重复切片
+	fire(timer, timer.at.Add(-time.Second))
+	timer = clock.next(t)
+	if want := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC); !timer.at.Equal(want) {
+		t.Fatalf("second slice at %v, want %v", timer.at, want)
+	}
+	fire(timer, timer.at)
+	timer = clock.next(t)
+	if want := time.Date(2024, 1, 15, 13, 0, 0, 0, time.UTC); !timer.at.Equal(want) {
+		t.Fatalf("third slice at %v, want %v", timer.at, want)
+	}
+
+	close(stop)
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("sliceByTime did not stop")
+	}
+}
+
+func TestNeverFiringScheduleIsRejected(t *testing.T) {
+	dir := t.TempDir()
+	never := ScheduleFunc(func(time.Time) time.Time { return time.Time{} })
+	err := InitGlobLogWithConfigE(Config{Name: filepath.Join(dir, "glob.log"), ArchiveDir: filepath.Join(dir, "logs"), Schedule: never})
+	var ge *GlobLogError
+	if !errors.As(err, &ge) || ge.Op != GlobLogOpSchedule || !errors.Is(err, ErrScheduleNeverFires) {
+		resetGlobLog()
+		t.Fatalf("InitGlobLogWithConfigE = %v, want a schedule error", err)
+	}
+	if EnableGlobLog {
+		t.Fatal("glob log enabled with a schedule that never fires")
+	}
+	if _, err = NewFileSink(Config{Name: filepath.Join(dir, "sink.log"), Schedule: never}, LevelDefault); !errors.Is(err, ErrScheduleNeverFires) {
+		t.Fatalf("NewFileSink = %v, want ErrScheduleNeverFires", err)
+	}
+}
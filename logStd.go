@@ -0,0 +1,212 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"github.com/fexli/logger/logcolor"
+	"log"
+	"os"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// stdLogWriter 将标准库log包写入的每条消息转换为一条日志
+type stdLogWriter struct {
+	l     *Logger
+	level LogLevel
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	if !w.l.wants(w.level) {
+		return len(p), nil
+	}
+	msg := strings.TrimSuffix(string(p), "\n")
+	w.l.emit(&LoggInfo{
+		Ts:     float64(time.Now().UnixMilli()) / 1000,
+		Cur:    stdLogCurInfo(),
+		Level:  w.level,
+		Info:   logcolor.New().WithText(msg),
+		Fields: w.l.fields,
+		from:   w.l,
+	}, true, true)
+	return len(p), nil
+}
+
+// stdLogCurInfo 跳过log包与本包的调用帧，返回log.Printf等函数的实际调用位置
+func stdLogCurInfo() *CurInfo {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for f, again := frames.Next(); ; f, again = frames.Next() {
+		if !strings.HasPrefix(f.Function, "log.") && !strings.HasPrefix(f.Function, "github.com/fexli/logger.") {
+			return &CurInfo{
+				Function: f.Function,
+				Line:     f.Line,
+				FilePath: path.Dir(f.File),
+				FileName: path.Base(f.File),
+			}
+		}
+		if !again {
+			return emptyCurInfo
+		}
+	}
+}
+
+// StdLog 返回一个输出到当前Logger的标准库*log.Logger，每次调用Print系列函数产生一条level等级的日志
+//
+// e.g.
+//
+//	server := &http.Server{ErrorLog: logger.GetLogger("http", false).StdLog(logger.LevelError)}
+func (l *Logger) StdLog(level LogLevel) *log.Logger {
+	return log.New(&stdLogWriter{l: l, level: level}, "", 0)
+}
+
+// RedirectStdLog 将标准库log包的默认Logger（log.Printf等）重定向到l，返回用于恢复原有输出、前缀与标志的函数
+//
+// e.g.
+//
+//	restore := logger.RedirectStdLog(logger.RootLogger, logger.LevelCommon)
+//	defer restore()
+func RedirectStdLog(l *Logger, level LogLevel) func() {
+	std := log.Default()
+	flags, prefix, writer := std.Flags(), std.Prefix(), std.Writer()
+	std.SetFlags(0)
+	std.SetPrefix("")
+	std.SetOutput(&stdLogWriter{l: l, level: level})
+	return func() {
+		std.SetFlags(flags)
+		std.SetPrefix(prefix)
+		std.SetOutput(writer)
+	}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Stderr Capture
+
+var (
+	// errStderrUnsupported 当前平台不支持重定向标准错误
+	errStderrUnsupported = errors.New("stderr redirection: " + runtime.GOARCH + " architecture with " + runtime.GOOS + " system is not supported")
+
+	// stderrMu 串行化接管的开始与停止，当前的接管保存于stderrCapture（*stderrCapturer），读取时无需持有stderrMu
+	stderrMu      sync.Mutex
+	stderrCapture atomic.Value
+	stderrCurInfo = &CurInfo{
+		Function: "os.Stderr",
+		FileName: "stderr",
+		FilePath: "",
+		Line:     0,
+	}
+)
+
+// stderrCapturer 通过管道接管标准错误，将读取到的内容按行转换为日志
+type stderrCapturer struct {
+	l     *Logger
+	level LogLevel
+	saved *os.File
+	r, w  *os.File
+	done  chan struct{}
+	// 崩溃信息写入全局日志文件（由InitGlobLogWithConfig接管），否则写入原标准错误
+	crashToGlob bool
+}
+
+func (c *stderrCapturer) run() {
+	defer close(c.done)
+	reader := bufio.NewReader(c.r)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if line = bytes.TrimRight(line, "\r\n"); len(line) > 0 {
+			c.l.emit(&LoggInfo{
+				Ts:     float64(time.Now().UnixMilli()) / 1000,
+				Cur:    stderrCurInfo,
+				Level:  c.level,
+				Info:   logcolor.New().WithText(string(line)),
+				Fields: c.l.fields,
+				from:   c.l,
+			}, true, true)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// CaptureStderr 将进程的标准错误（包括第三方库与运行时直接写入os.Stderr的内容）接管到l，
+// 每一行作为一条level等级的日志输出，返回用于停止接管并恢复原标准错误的函数。
+// 重复调用会先停止上一次的接管。
+//
+// 进程因panic或运行时致命错误退出时，读取管道的goroutine来不及处理错误信息，因此接管期间该信息会另外写入原标准错误
+// （通过InitGlobLogWithConfig接管时写入全局日志文件）；该功能需要Go 1.23及以上，更早的版本中这部分信息会丢失。
+//
+// e.g.
+//
+//	stop, err := logger.CaptureStderr(logger.RootLogger, logger.LevelError)
+//	if err == nil {
+//		defer stop()
+//	}
+func CaptureStderr(l *Logger, level LogLevel) (func() error, error) {
+	return captureStderr(l, level, nil)
+}
+
+// captureStderr 接管标准错误，crash不为nil时将崩溃信息写入crash（全局日志文件），否则写入原标准错误
+func captureStderr(l *Logger, level LogLevel, crash *os.File) (func() error, error) {
+	stderrMu.Lock()
+	defer stderrMu.Unlock()
+	if old := activeCapture(); old != nil {
+		if err := old.stop(); err != nil {
+			return nil, err
+		}
+		stderrCapture.Store((*stderrCapturer)(nil))
+	}
+	saved, err := dupStderr()
+	if err != nil {
+		return nil, err
+	}
+	r, w, err := os.Pipe()
+	if err != nil {
+		_ = saved.Close()
+		return nil, err
+	}
+	if err = redirectStderr(w); err != nil {
+		_ = saved.Close()
+		_ = r.Close()
+		_ = w.Close()
+		return nil, err
+	}
+	c := &stderrCapturer{l: l, level: level, saved: saved, r: r, w: w, done: make(chan struct{}), crashToGlob: crash != nil}
+	if crash == nil {
+		crash = saved
+	}
+	_ = setCrashOutput(crash)
+	go c.run()
+	stderrCapture.Store(c)
+	return func() error {
+		stderrMu.Lock()
+		defer stderrMu.Unlock()
+		if activeCapture() != c {
+			return nil
+		}
+		stderrCapture.Store((*stderrCapturer)(nil))
+		return c.stop()
+	}, nil
+}
+
+// activeCapture 返回当前的标准错误接管，未接管时返回nil
+func activeCapture() *stderrCapturer {
+	c, _ := stderrCapture.Load().(*stderrCapturer)
+	return c
+}
+
+// stop 恢复原标准错误，并在输出管道中剩余的内容后关闭管道
+func (c *stderrCapturer) stop() error {
+	_ = setCrashOutput(nil)
+	err := restoreStderr(c.saved)
+	_ = c.w.Close()
+	<-c.done
+	_ = c.r.Close()
+	return err
+}
@@ -0,0 +1,25 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/fexli/logger"
+)
+
+func TestStdLog(t *testing.T) {
+	l := logger.GetLogger(t.Name(), false)
+	l.SetLogLevel(logger.LevelDefault &^ logger.LevelDebug)
+	l.StdLog(logger.LevelCommon).Printf("hello %d", 1)
+	logs := l.GetLogs(0, logger.LevelDefault, -1)
+	if len(logs) != 1 || logs[0].Info.GetRawString() != "hello 1" {
+		t.Fatalf("unexpected history: %v", logs)
+	}
+	if cur := logs[0].Caller(); cur.FileName != "logStd_test.go" {
+		t.Fatalf("caller = %+v, want logStd_test.go", cur)
+	}
+
+	l.StdLog(logger.LevelDebug).Print("disabled")
+	if n := len(l.GetLogs(0, logger.LevelDefault, -1)); n != 1 {
+		t.Fatalf("disabled level recorded, history has %d entries", n)
+	}
+}
@@ -39,6 +39,36 @@ func (b *Color) WriteStart(writer io.StringWriter) {
 	writer.WriteString(b.String())
 }
 
+// AppendStart 将颜色控制串追加到buf，与WriteStart输出相同
+func (b *Color) AppendStart(buf []byte) []byte {
+	if b == nil {
+		return buf
+	}
+	co := b.Options.Code()
+	id := ""
+	if b.Identity != nil {
+		id = b.Identity.Code()
+	}
+	if len(co) == 0 && len(id) == 0 {
+		return buf
+	}
+	buf = append(buf, startCtr...)
+	buf = append(buf, co...)
+	if len(co) > 0 && len(id) > 0 {
+		buf = append(buf, ';')
+	}
+	buf = append(buf, id...)
+	return append(buf, endCtrl...)
+}
+
+// AppendEnd 将颜色重置控制串追加到buf，与WriteEnd输出相同
+func (b *Color) AppendEnd(buf []byte) []byte {
+	if b == nil || (b.Options&opMask == 0 && (b.Identity == nil || b.Identity.IsEmpty())) {
+		return buf
+	}
+	return append(buf, resetCtr...)
+}
+
 func (b *Color) WriteEnd(writer io.StringWriter) {
 	if b == nil || (b.Options&opMask == 0 && (b.Identity == nil || b.Identity.IsEmpty())) {
 		return
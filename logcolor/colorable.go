@@ -3,6 +3,7 @@ package logcolor
 import (
 	"errors"
 	"github.com/xo/terminfo"
+	"io"
 	"os"
 	"sync"
 )
@@ -10,6 +11,7 @@ import (
 type WriterConsole struct {
 	syncMutex  sync.Mutex
 	std        *os.File
+	out        io.Writer
 	fd         uintptr // handle to the console
 	colorLevel terminfo.ColorLevel
 }
@@ -22,13 +24,24 @@ const (
 
 var (
 	InvalidConsole = errors.New("invalid console")
-	lf             = []byte("\n")
 )
 
+// linePool 复用渲染一行文本所用的缓冲区
+var linePool = sync.Pool{
+	New: func() interface{} {
+		b := make([]byte, 0, 256)
+		return &b
+	},
+}
+
+// maxPooledLine 超过该容量的缓冲区不放回linePool，避免偶发的超长日志长期占用内存
+const maxPooledLine = 64 << 10
+
 func Colorable(file *os.File) *WriterConsole {
 	w := &WriterConsole{
 		syncMutex: sync.Mutex{},
 		std:       file,
+		out:       file,
 	}
 	w.fd = file.Fd()
 	w.colorLevel = terminfo.ColorLevelNone
@@ -38,15 +51,42 @@ func Colorable(file *os.File) *WriterConsole {
 	return w
 }
 
+// NewWriterConsole 创建写入w的WriterConsole，w为*os.File时与Colorable相同，否则默认不输出颜色
+func NewWriterConsole(w io.Writer) *WriterConsole {
+	if f, ok := w.(*os.File); ok {
+		return Colorable(f)
+	}
+	return &WriterConsole{
+		out:        w,
+		colorLevel: terminfo.ColorLevelNone,
+	}
+}
+
 func (w *WriterConsole) EnableColor() {
+	w.syncMutex.Lock()
 	w.colorLevel = colorLevel
+	w.syncMutex.Unlock()
 }
 func (w *WriterConsole) ForceSetColor(colorMode terminfo.ColorLevel) *WriterConsole {
+	w.syncMutex.Lock()
 	w.colorLevel = colorMode
+	w.syncMutex.Unlock()
 	return w
 }
 func (w *WriterConsole) DisableColor() {
+	w.syncMutex.Lock()
 	w.colorLevel = terminfo.ColorLevelNone
+	w.syncMutex.Unlock()
+}
+
+// Colored 是否输出颜色控制序列
+func (w *WriterConsole) Colored() bool {
+	if w == nil {
+		return false
+	}
+	w.syncMutex.Lock()
+	defer w.syncMutex.Unlock()
+	return w.colorLevel != terminfo.ColorLevelNone
 }
 
 const InvalidHandle = ^uintptr(0)
@@ -59,12 +99,11 @@ func (w *WriterConsole) Write(text *LogTextCtx, sync bool) (bool, error) {
 		w.syncMutex.Lock()
 		defer w.syncMutex.Unlock()
 	}
-	if w.colorLevel != terminfo.ColorLevelNone {
-		text.WriteBytes(w.std, text.Color)
-	} else {
-		text.WriteRawBytes(w.std)
-	}
-	return true, nil
+	buf := linePool.Get().(*[]byte)
+	*buf = w.render((*buf)[:0], text)
+	_, err := w.out.Write(*buf)
+	putLine(buf)
+	return err == nil, err
 }
 
 func (w *WriterConsole) Println(text *LogTextCtx) {
@@ -73,10 +112,40 @@ func (w *WriterConsole) Println(text *LogTextCtx) {
 	}
 	w.syncMutex.Lock()
 	defer w.syncMutex.Unlock()
+	buf := linePool.Get().(*[]byte)
+	*buf = append(w.render((*buf)[:0], text), '\n')
+	_, _ = w.out.Write(*buf)
+	putLine(buf)
+}
+
+// WriteLine 将已渲染的一行文本与换行符以一次写入输出，line在返回后即可复用
+func (w *WriterConsole) WriteLine(line []byte) {
+	if w == nil || w.fd == InvalidHandle {
+		return
+	}
+	w.syncMutex.Lock()
+	defer w.syncMutex.Unlock()
+	if len(line) < cap(line) {
+		// 借用line的剩余容量写入换行符，避免额外的写入调用
+		_, _ = w.out.Write(append(line, '\n'))
+		return
+	}
+	buf := linePool.Get().(*[]byte)
+	*buf = append(append((*buf)[:0], line...), '\n')
+	_, _ = w.out.Write(*buf)
+	putLine(buf)
+}
+
+// render 按颜色设置将text追加到b
+func (w *WriterConsole) render(b []byte, text *LogTextCtx) []byte {
 	if w.colorLevel != terminfo.ColorLevelNone {
-		text.WriteBytes(w.std, text.Color)
-	} else {
-		text.WriteRawBytes(w.std)
+		return text.AppendBytes(b, text.Color)
+	}
+	return text.AppendRawBytes(b)
+}
+
+func putLine(buf *[]byte) {
+	if cap(*buf) <= maxPooledLine {
+		linePool.Put(buf)
 	}
-	w.std.Write(lf)
 }
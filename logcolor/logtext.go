@@ -1,7 +1,6 @@
 package logcolor
 
 import (
-	"bytes"
 	"io"
 	"unsafe"
 )
@@ -49,14 +48,31 @@ func (t *LogTextCtx) WriteBytes(buffer io.StringWriter, prevMask *Color) {
 	}
 }
 
+// AppendBytes appends the text sequence with `\x1b[` colored control
+// sequence to b, the output is the same as WriteBytes.
+func (t *LogTextCtx) AppendBytes(b []byte, prevMask *Color) []byte {
+	if t == nil {
+		return b
+	}
+	if len(t.InnerLog) != 0 {
+		for _, ctx := range t.InnerLog {
+			b = ctx.AppendBytes(b, prevMask)
+		}
+	} else if t.Log != "" {
+		mask := prevMask.MergeTo(t.Color)
+		b = mask.AppendStart(b)
+		b = append(b, t.Log...)
+		b = mask.AppendEnd(b)
+	}
+	return b
+}
+
 // GetBytes return the text sequence control with `\x1b[` colored control sequence.
 func (t *LogTextCtx) GetBytes() []byte {
 	if t == nil {
 		return []byte{}
 	}
-	buffer := &bytes.Buffer{}
-	t.WriteBytes(buffer, t.Color)
-	return buffer.Bytes()
+	return t.AppendBytes(make([]byte, 0, 64), t.Color)
 }
 
 // WriteRawBytes write the text sequence with
@@ -77,14 +93,31 @@ func (t *LogTextCtx) WriteRawBytes(buffer io.StringWriter) {
 	}
 }
 
+// AppendRawBytes appends the text sequence with no color control
+// sequence to b, the output is the same as WriteRawBytes.
+func (t *LogTextCtx) AppendRawBytes(b []byte) []byte {
+	if t == nil {
+		return b
+	}
+	if len(t.InnerLog) != 0 {
+		for _, ctx := range t.InnerLog {
+			b = ctx.AppendRawBytes(b)
+		}
+	} else {
+		b = append(b, t.Log...)
+	}
+	return b
+}
+
 // GetRawBytes return the text sequence by WriteRawBytes().
 func (t *LogTextCtx) GetRawBytes() []byte {
 	if t == nil {
 		return []byte{}
 	}
-	buffer := &bytes.Buffer{}
-	t.WriteRawBytes(buffer)
-	return buffer.Bytes()
+	if len(t.InnerLog) == 0 {
+		return []byte(t.Log)
+	}
+	return t.AppendRawBytes(make([]byte, 0, 64))
 }
 
 // GetString returns the text sequence generated by LogTextCtx.GetBytes().
@@ -95,6 +128,9 @@ func (t *LogTextCtx) GetString() string {
 
 // GetRawString returns the text sequence generated by LogTextCtx.GetRawBytes().
 func (t *LogTextCtx) GetRawString() string {
+	if t != nil && len(t.InnerLog) == 0 {
+		return t.Log
+	}
 	info := t.GetRawBytes()
 	return *(*string)(unsafe.Pointer(&info))
 }
@@ -2,18 +2,21 @@ package logger
 
 import (
 	"container/list"
+	"errors"
 	"fmt"
 	"github.com/ahmetb/go-linq/v3"
 	"github.com/fexli/logger/logcolor"
-	"github.com/fexli/logger/utils"
 	"github.com/modern-go/reflect2"
 	"github.com/xo/terminfo"
+	"io"
 	"os"
+	"os/signal"
 	"path"
 	"runtime"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
-	"unsafe"
 )
 
 var (
@@ -23,18 +26,31 @@ var (
 		FilePath: "unknown",
 		Line:     0,
 	}
-	colorableStdout            = logcolor.Colorable(os.Stdout)
 	GlobalFileHandler *os.File = nil
+	// 控制台输出，保存*logcolor.WriterConsole
+	consoleOut atomic.Value
 
-	pool                = make(map[string]*Logger)
+	pool        = make(map[string]*Logger)
+	poolMu      sync.Mutex
+	globMu      sync.Mutex
 	RootLogger  *Logger = nil
 	LogPrefix           = make(map[LogLevel]*logcolor.LogTextCtx)
 	TimeColor           = logcolor.NewColor(logcolor.RGB(127, 255, 237))
 	MemCurColor         = logcolor.NewColor(logcolor.RGB(203, 127, 255))
 	StackColor          = logcolor.NewColor(logcolor.RGB(168, 209, 135))
+	TraceColor          = logcolor.NewColor(logcolor.RGB(128, 128, 128))
 
 	EnableGlobLog = false
 	GlobLogFilter = LevelDefault
+
+	globOut logFile
+
+	// 串行化全局日志的初始化与关闭，获取日志文件锁时只持有globInitMu而不持有globMu
+	globInitMu sync.Mutex
+	// 以下由globMu保护：停止定时切片与信号处理的通道、停止接管标准错误的函数与初始化前的标准错误
+	globStop        chan struct{}
+	globStopCapture func() error
+	globSavedStderr *os.File
 )
 
 const (
@@ -52,6 +68,8 @@ const (
 )
 
 func init() {
+	consoleOut.Store(logcolor.Colorable(os.Stdout))
+
 	// 内部日志Logger初始化
 
 	RootLogger = GetLogger("sys", false)
@@ -68,19 +86,33 @@ func init() {
 	}
 }
 
+// console 返回当前的控制台输出
+func console() *logcolor.WriterConsole {
+	return consoleOut.Load().(*logcolor.WriterConsole)
+}
+
+// SetConsoleOutput 设置控制台日志的输出目标，默认为os.Stdout；w为终端以外的目标时默认不输出颜色
+//
+// e.g.
+//
+//	logger.SetConsoleOutput(io.Discard) // 只写入日志文件与Printer，不输出到控制台
+func SetConsoleOutput(w io.Writer) {
+	consoleOut.Store(logcolor.NewWriterConsole(w))
+}
+
 // DisableColor 禁用日志颜色
 func DisableColor() {
-	colorableStdout.DisableColor()
+	console().DisableColor()
 }
 
 // EnableColor 启用日志颜色
 func EnableColor() {
-	colorableStdout.EnableColor()
+	console().EnableColor()
 }
 
 // ForceSetColor 强制设置日志颜色
 func ForceSetColor(colorMode terminfo.ColorLevel) {
-	colorableStdout.ForceSetColor(colorMode)
+	console().ForceSetColor(colorMode)
 }
 
 // SetGlobLogFilter 设置全局日志记录等级，默认为LevelDefault，即记录所有等级到日志文件，此项目受到Logger本身logLevel限制
@@ -89,7 +121,9 @@ func ForceSetColor(colorMode terminfo.ColorLevel) {
 //
 //	logger.SetGlobLogFilter(logger.LevelFatal | logger.LevelError) // 设置全局日志记录等级为Fatal和Error
 func SetGlobLogFilter(filter LogLevel) {
+	globMu.Lock()
 	GlobLogFilter = filter
+	globMu.Unlock()
 }
 
 // _pause
@@ -99,63 +133,105 @@ func _pause() {
 	_, _ = os.Stdin.Read(b)
 }
 
-// InitGlobLog 初始化全局日志，name为日志文件名，如果name为空，则使用默认文件名，可选logDesc为日志文件描述
+// GlobLogOp 初始化全局日志时失败的步骤
+type GlobLogOp string
+
+const (
+	GlobLogOpLock     GlobLogOp = "lock"     // 获取日志文件锁
+	GlobLogOpMkdir    GlobLogOp = "mkdir"    // 创建归档目录
+	GlobLogOpRename   GlobLogOp = "rename"   // 归档上次运行留下的日志文件
+	GlobLogOpOpen     GlobLogOp = "open"     // 打开日志文件
+	GlobLogOpHeader   GlobLogOp = "header"   // 写入日志文件首行描述
+	GlobLogOpRedirect GlobLogOp = "redirect" // 将标准错误重定向到日志文件
+	GlobLogOpSchedule GlobLogOp = "schedule" // 检查定时切片计划
+)
+
+// ErrGlobLogLocked 日志文件已被其他进程锁定（Config.Lock为LockFail时）
+var ErrGlobLogLocked = errors.New("logger: glob log file is locked by another process")
+
+// errLockUnsupported 当前平台不支持文件锁，此时不加锁继续初始化
+var errLockUnsupported = errors.New("logger: file lock is not supported on " + runtime.GOOS)
+
+// GlobLogError InitGlobLogE与InitGlobLogWithConfigE返回的错误，Op为失败的步骤，Path为相关的文件路径
+type GlobLogError struct {
+	Op   GlobLogOp
+	Path string
+	Err  error
+}
+
+func (e *GlobLogError) Error() string {
+	var pe *os.PathError
+	var le *os.LinkError
+	if errors.As(e.Err, &pe) || errors.As(e.Err, &le) {
+		return "logger: init glob log: " + string(e.Op) + ": " + e.Err.Error()
+	}
+	return "logger: init glob log: " + string(e.Op) + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *GlobLogError) Unwrap() error {
+	return e.Err
+}
+
+// exitCode 返回InitGlobLog与InitGlobLogWithConfig在该步骤失败时的退出码
+func (e *GlobLogError) exitCode() int {
+	switch e.Op {
+	case GlobLogOpMkdir, GlobLogOpRename:
+		return 0xD01
+	case GlobLogOpHeader:
+		return 0xD02
+	case GlobLogOpLock:
+		return 0xD03
+	}
+	return 0xD00
+}
+
+// InitGlobLog 初始化全局日志，name为日志文件名，如果name为空，则使用默认文件名，可选logDesc为日志文件描述，
+// 失败时等待输入回车后退出进程，需要自行处理错误时请使用InitGlobLogE
 //
 // e.g.
 //
 //	logger.InitGlobLog("globlog.log", "awesomeProgram v0.1")
 func InitGlobLog(name string, logDesc ...string) {
-	if EnableGlobLog {
-		return
-	}
-	EnableGlobLog = true
-	if name == "" {
-		name = "globlog.log"
-	}
-	_ = os.Mkdir("logs", 0764)
-	if info, err := os.Stat(name); info != nil && err == nil {
-		if err = os.Rename(name, path.Join("logs", info.ModTime().Format("2006-01-02-15-04")+"."+utils.RandomStr(2, false, "")+".log")); err != nil {
-			_pause()
-			os.Exit(0xD01)
-		}
-	}
-	file, e := os.OpenFile(name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_SYNC, 0764)
-	if e != nil {
-		_pause()
-		os.Exit(0xD00)
-	}
+	config := Config{Name: name}
 	if len(logDesc) > 0 {
-		_, err := file.Write([]byte(logDesc[0] + " Running Log [Started At " + time.Now().String() + "]\n"))
-		if err != nil {
-			_pause()
-			os.Exit(0xD02)
-		}
+		config.Desc = logDesc[0]
 	}
-	GlobalFileHandler = file
+	InitGlobLogWithConfig(config)
+}
 
-	initErr()
+// InitGlobLogE 与InitGlobLog相同，但失败时返回*GlobLogError而不退出进程，此时全局日志保持未初始化状态，可以再次调用
+//
+// e.g.
+//
+//	if err := logger.InitGlobLogE("globlog.log", "awesomeProgram v0.1"); err != nil {
+//		log.Fatal(err)
+//	}
+func InitGlobLogE(name string, logDesc ...string) error {
+	config := Config{Name: name}
+	if len(logDesc) > 0 {
+		config.Desc = logDesc[0]
+	}
+	return InitGlobLogWithConfigE(config)
 }
 
 func defaultConfig() Config {
 	return Config{
-		Name: "globlog.log",
-		Desc: "",
-		OldLogPath: func(info os.FileInfo) string {
-			return path.Join("logs", info.ModTime().Format("2006-01-02-15-04")+"."+utils.RandomStr(2, false, "")+".log")
-		},
+		Name:          "globlog.log",
+		Desc:          "",
+		ArchiveName:   DefaultArchiveName,
+		BufferSize:    64 << 10,
+		FlushInterval: time.Second,
+		SyncLevel:     LevelFatal | LevelError,
+		Clock:         SystemClock,
+		ArchiveDir:    "logs",
+		ReopenSignal:  defaultReopenSignal,
+		StderrLogger:  RootLogger,
+		StderrLevel:   LevelError,
 	}
 }
 
-// InitGlobLogWithConfig 初始化全局日志，name为日志文件名，如果name为空，则使用默认文件名，可选logDesc为日志文件描述
-//
-// e.g.
-//
-//	logger.InitGlobLogWithConfig("globlog.log", "awesomeProgram v0.1")
-func InitGlobLogWithConfig(config ...Config) {
-	if EnableGlobLog {
-		return
-	}
-	EnableGlobLog = true
+// mergeConfig 以config中的非零值覆盖默认配置
+func mergeConfig(config ...Config) Config {
 	var current Config = defaultConfig()
 	if len(config) != 0 {
 		if config[0].Name != "" {
@@ -167,67 +243,424 @@ func InitGlobLogWithConfig(config ...Config) {
 		if config[0].OldLogPath != nil {
 			current.OldLogPath = config[0].OldLogPath
 		}
+		if config[0].ArchiveName != "" {
+			current.ArchiveName = config[0].ArchiveName
+		}
 		if config[0].MaxLogTime != 0 {
 			current.MaxLogTime = config[0].MaxLogTime
 		}
 		if len(config[0].SliceWhen) != 0 {
 			current.SliceWhen = config[0].SliceWhen
 		}
-	}
-	_ = os.Mkdir("logs", 0764)
-	if info, err := os.Stat(current.Name); info != nil && err == nil {
-		if err = os.Rename(current.Name, current.OldLogPath(info)); err != nil {
-			_pause()
-			os.Exit(0xD01)
+		if config[0].Schedule != nil {
+			current.Schedule = config[0].Schedule
+		}
+		if config[0].Clock != nil {
+			current.Clock = config[0].Clock
+		}
+		if config[0].MaxSize > 0 {
+			current.MaxSize = config[0].MaxSize
+		}
+		if config[0].ArchiveDir != "" {
+			current.ArchiveDir = config[0].ArchiveDir
+		}
+		current.Compress = config[0].Compress
+		current.MaxArchives = config[0].MaxArchives
+		current.MaxArchiveSize = config[0].MaxArchiveSize
+		current.MaxArchiveAge = config[0].MaxArchiveAge
+		current.Durability = config[0].Durability
+		if config[0].BufferSize > 0 {
+			current.BufferSize = config[0].BufferSize
+		}
+		if config[0].FlushInterval > 0 {
+			current.FlushInterval = config[0].FlushInterval
+		}
+		current.SyncEvery = config[0].SyncEvery
+		if config[0].SyncLevel != 0 {
+			current.SyncLevel = config[0].SyncLevel
+		}
+		current.Lock = config[0].Lock
+		current.ReopenOnSignal = config[0].ReopenOnSignal
+		if config[0].ReopenSignal != nil {
+			current.ReopenSignal = config[0].ReopenSignal
+		}
+		current.CaptureStderr = config[0].CaptureStderr
+		if config[0].StderrLogger != nil {
+			current.StderrLogger = config[0].StderrLogger
+		}
+		if config[0].StderrLevel != 0 {
+			current.StderrLevel = config[0].StderrLevel
 		}
 	}
-	file, e := os.OpenFile(current.Name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_SYNC, 0764)
-	if e != nil {
-		_pause()
-		os.Exit(0xD00)
+	if current.OldLogPath == nil {
+		current.archiveName = newArchiveTemplate(current.ArchiveDir, current.ArchiveName, current.Name)
+		current.OldLogPath = current.archiveName.path
 	}
-	if current.Desc != "" {
-		_, err := file.Write([]byte(current.Desc + " Running Log [Started At " + time.Now().String() + "]\n"))
-		if err != nil {
-			_pause()
-			os.Exit(0xD02)
-		}
+	return current
+}
+
+// InitGlobLogWithConfig 以config初始化全局日志，未设置的配置项使用默认值，
+// 失败时等待输入回车后退出进程，需要自行处理错误时请使用InitGlobLogWithConfigE
+//
+// e.g.
+//
+//	logger.InitGlobLogWithConfig(logger.Config{Name: "globlog.log", Desc: "awesomeProgram v0.1"})
+func InitGlobLogWithConfig(config ...Config) {
+	globInitMu.Lock()
+	defer globInitMu.Unlock()
+	current := mergeConfig(config...)
+	lock, gerr := lockGlob(&current)
+	globMu.Lock()
+	if EnableGlobLog {
+		globMu.Unlock()
+		releaseLock(lock)
+		return
 	}
-	GlobalFileHandler = file
-	if current.MaxLogTime != 0 || len(current.SliceWhen) != 0 {
-		go sliceGlobByTime(current)
+	if gerr == nil {
+		gerr = openGlob(&current, lock)
 	}
+	if gerr != nil {
+		globMu.Unlock()
+		_pause()
+		exit(gerr.exitCode()) // 不会返回
+	}
+	startGlob(current)
+	file := GlobalFileHandler
+	globMu.Unlock()
+	if current.CaptureStderr && captureGlobStderr(current, file) {
+		return
+	}
+	globMu.Lock()
+	defer globMu.Unlock()
+	globSavedStderr, _ = dupStderr()
 	initErr()
 }
 
-func sliceGlobByTime(current Config) {
+// InitGlobLogWithConfigE 与InitGlobLogWithConfig相同，但失败时返回*GlobLogError而不退出进程，此时全局日志保持未初始化状态，可以再次调用。
+// 当前平台不支持重定向标准错误时不视为错误
+//
+// e.g.
+//
+//	err := logger.InitGlobLogWithConfigE(logger.Config{Name: "globlog.log"})
+//	var ge *logger.GlobLogError
+//	if errors.As(err, &ge) && ge.Op == logger.GlobLogOpOpen {
+//		// 日志文件无法打开
+//	}
+func InitGlobLogWithConfigE(config ...Config) error {
+	globInitMu.Lock()
+	defer globInitMu.Unlock()
+	current := mergeConfig(config...)
+	lock, gerr := lockGlob(&current)
+	if gerr != nil {
+		return gerr
+	}
+	globMu.Lock()
+	if EnableGlobLog {
+		globMu.Unlock()
+		releaseLock(lock)
+		return nil
+	}
+	if err := openGlob(&current, lock); err != nil {
+		globMu.Unlock()
+		return err
+	}
+	startGlob(current)
+	file := GlobalFileHandler
+	globMu.Unlock()
+	if current.CaptureStderr && captureGlobStderr(current, file) {
+		return nil
+	}
+	globMu.Lock()
+	defer globMu.Unlock()
+	saved, _ := dupStderr()
+	if err := redirectStderr(GlobalFileHandler); err != nil && err != errStderrUnsupported {
+		releaseLock(saved)
+		close(globStop)
+		globStop = nil
+		_ = globOut.close()
+		globOut = logFile{}
+		GlobalFileHandler = nil
+		EnableGlobLog = false
+		return &GlobLogError{Op: GlobLogOpRedirect, Path: current.Name, Err: err}
+	}
+	globSavedStderr = saved
+	return nil
+}
+
+// captureGlobStderr 将标准错误接管到current.StderrLogger，崩溃信息写入全局日志文件file，接管成功时返回true。
+// 停止之前的接管时需要等待其剩余内容输出为日志，而输出日志需要globMu，因此调用方不能持有globMu
+func captureGlobStderr(current Config, file *os.File) bool {
+	stop, err := captureStderr(current.StderrLogger, current.StderrLevel, file)
+	if err != nil {
+		return false
+	}
+	globMu.Lock()
+	globStopCapture = stop
+	// 接管期间全局日志文件可能已被切片
+	if GlobalFileHandler != nil && GlobalFileHandler != file {
+		_ = setCrashOutput(GlobalFileHandler)
+	}
+	globMu.Unlock()
+	return true
+}
+
+// CloseGlobLog 等待全部Logger异步队列中已有的日志输出完成，将全局日志文件缓冲区中的日志写入文件并执行fsync，
+// 然后关闭日志文件、释放日志文件锁、停止定时切片与信号处理，并恢复初始化前的标准错误；之后的日志不再写入文件，
+// 可以再次调用InitGlobLog系列函数初始化。未初始化全局日志时直接返回
+//
+// e.g.
+//
+//	logger.InitGlobLogWithConfig(logger.Config{Durability: logger.DurabilityBuffered})
+//	defer logger.CloseGlobLog()
+func CloseGlobLog() error {
+	globInitMu.Lock()
+	defer globInitMu.Unlock()
+	Flush()
+	globMu.Lock()
+	if !EnableGlobLog {
+		globMu.Unlock()
+		return nil
+	}
+	if globStop != nil {
+		close(globStop)
+	}
+	stopCapture, saved := globStopCapture, globSavedStderr
+	globStop, globStopCapture, globSavedStderr = nil, nil, nil
+	err := globOut.close()
+	globOut = logFile{}
+	GlobalFileHandler = nil
+	EnableGlobLog = false
+	globMu.Unlock()
+	// 停止接管时需要等待管道中剩余的内容输出为日志，因此在释放globMu之后进行
+	if stopCapture != nil {
+		_ = stopCapture()
+	}
+	if saved != nil {
+		_ = restoreStderr(saved)
+	}
+	return err
+}
+
+// exit 等待异步队列输出完成并将全局日志文件缓冲区中的日志写入文件后退出进程，调用方不能持有globMu
+func exit(code int) {
+	_ = Sync()
+	os.Exit(code)
+}
+
+// exitHoldingGlob 在持有globMu时退出进程，exit不会返回，因此调用方延迟执行的解锁不会发生
+func exitHoldingGlob(code int) {
+	globMu.Unlock()
+	exit(code)
+}
+
+// lockGlob 在不持有globMu的情况下获取全局日志文件锁，LockWait时可能长时间阻塞而不影响其他goroutine输出日志；
+// 全局日志已初始化时不加锁，使用LockPerPID时会修改current.Name
+func lockGlob(current *Config) (*os.File, *GlobLogError) {
+	globMu.Lock()
+	enabled := EnableGlobLog
+	globMu.Unlock()
+	if enabled {
+		return nil, nil
+	}
+	return lockLogFile(current)
+}
+
+// releaseLock 释放lockGlob获取的日志文件锁
+func releaseLock(lock *os.File) {
+	if lock != nil {
+		_ = lock.Close()
+	}
+}
+
+// openGlob 以lockGlob获取的日志文件锁打开全局日志文件，调用方需持有globMu，失败时释放锁且不修改全局状态
+func openGlob(current *Config, lock *os.File) *GlobLogError {
+	if err := globOut.openLocked(current, lock); err != nil {
+		return err
+	}
+	EnableGlobLog = true
+	GlobalFileHandler = globOut.file
+	return nil
+}
+
+// startGlob 启动定时切片与重新打开日志文件的信号处理，CloseGlobLog时通过globStop停止，调用方需持有globMu
+func startGlob(current Config) {
+	globStop = make(chan struct{})
+	if current.timedSlice() {
+		go sliceByTime(current, globStop, func() {
+			globMu.Lock()
+			rotateGlob()
+			globMu.Unlock()
+		})
+	}
+	if current.Durability == DurabilityBuffered {
+		go flushByInterval(current.FlushInterval, globStop, func() {
+			globMu.Lock()
+			_ = globOut.flush()
+			globMu.Unlock()
+		})
+	}
+	if current.ReopenOnSignal && current.ReopenSignal != nil {
+		ch := make(chan os.Signal, 1)
+		signal.Notify(ch, current.ReopenSignal)
+		go reopenGlobOnSignal(ch, globStop)
+	}
+}
+
+func reopenGlobOnSignal(ch chan os.Signal, stop <-chan struct{}) {
+	defer signal.Stop(ch)
 	for {
-		next := findNextByWhen(current)
-		time.Sleep(next)
-		info, err := os.Stat(current.Name)
-		if err != nil {
-			continue
-		}
-		if GlobalFileHandler != nil {
-			fr := GlobalFileHandler
-			GlobalFileHandler = nil
-			fr.Close()
-		}
-		if err = os.Rename(current.Name, current.OldLogPath(info)); err != nil {
-			continue
+		select {
+		case <-ch:
+			// 标准错误可能已重定向到无法重新打开的日志文件，因此通过RootLogger输出到控制台
+			if err := ReopenGlobLog(); err != nil {
+				RootLogger.Error(WithContent("ReopenGlobLog failed:", err.Error()))
+			}
+		case <-stop:
+			return
 		}
-		file, e := os.OpenFile(current.Name, os.O_CREATE|os.O_TRUNC|os.O_WRONLY|os.O_SYNC, 0764)
-		if e != nil {
-			continue
+	}
+}
+
+// ReopenGlobLog 关闭并重新打开全局日志文件（以追加方式），重新将标准错误重定向到新文件并再次写入Desc首行描述，
+// 用于配合logrotate等外部工具在移动日志文件后切换到新文件；打开失败时继续使用原文件。未初始化全局日志时直接返回
+//
+// e.g. logrotate配置中的postrotate
+//
+//	kill -HUP $(cat /run/app.pid)
+func ReopenGlobLog() error {
+	globMu.Lock()
+	defer globMu.Unlock()
+	if !EnableGlobLog {
+		return nil
+	}
+	file, gerr := globOut.reopen()
+	if gerr != nil {
+		return gerr
+	}
+	if err := followGlobFile(file); err != nil && err != errStderrUnsupported {
+		_ = file.Close()
+		return &GlobLogError{Op: GlobLogOpRedirect, Path: globOut.config.Name, Err: err}
+	}
+	globOut.replace(file)
+	GlobalFileHandler = file
+	return nil
+}
+
+// followGlobFile 使标准错误跟随新的全局日志文件：未接管标准错误时将其重定向到file，
+// 接管标准错误且崩溃信息写入全局日志文件时改为写入file
+func followGlobFile(file *os.File) error {
+	capture := activeCapture()
+	if capture == nil {
+		return redirectStderr(file)
+	}
+	if capture.crashToGlob {
+		return setCrashOutput(file)
+	}
+	return nil
+}
+
+// globFileChanged 在切片等操作可能替换了全局日志文件后同步GlobalFileHandler，文件被替换时使标准错误跟随新文件，调用方需持有globMu
+func globFileChanged() {
+	if file := globOut.file; file != GlobalFileHandler {
+		GlobalFileHandler = file
+		if file != nil {
+			_ = followGlobFile(file)
 		}
-		if current.Desc != "" {
-			_, err := file.Write([]byte(current.Desc + " Running Log [Started At " + time.Now().String() + "]\n"))
-			if err != nil {
-				continue
+	}
+}
+
+// rotateGlob 切片当前全局日志文件，调用方需持有globMu
+func rotateGlob() {
+	globOut.rotate()
+	globFileChanged()
+}
+
+// globWanted 判断指定等级的日志是否需要写入全局日志文件
+func globWanted(level LogLevel) bool {
+	globMu.Lock()
+	defer globMu.Unlock()
+	return EnableGlobLog && GlobalFileHandler != nil && (level&GlobLogFilter != 0)
+}
+
+// writeGlob 向全局日志文件写入一行日志，写入失败时关闭文件并返回错误；
+// 切片中发生的错误由本次（或定时切片后的下一次）writeGlob返回，文件因此关闭时之后的日志不再写入文件，错误只返回一次
+func writeGlob(level LogLevel, info []byte) error {
+	globMu.Lock()
+	defer globMu.Unlock()
+	if !EnableGlobLog || (level&GlobLogFilter == 0) {
+		return nil
+	}
+	err := globOut.write(level, info)
+	globFileChanged()
+	return err
+}
+
+// Sync 等待全部Logger异步队列中已有的日志输出完成，并将全局日志文件缓冲区中的日志写入文件并执行fsync，
+// 使用DurabilityBuffered或DurabilityWrite时请在程序退出前调用Sync或CloseGlobLog。
+// 注意程序崩溃时运行时写入标准错误（已重定向到日志文件）的堆栈会早于缓冲区中尚未写入的日志，且这些日志会丢失
+//
+// e.g.
+//
+//	defer logger.Sync()
+func Sync() error {
+	Flush()
+	globMu.Lock()
+	defer globMu.Unlock()
+	return globOut.sync()
+}
+
+// String 返回日志等级的名称，多个等级组合时以"|"连接，LevelDefault返回ALL，0返回NONE
+func (l LogLevel) String() string {
+	if name := l.name(); name != "" {
+		return name
+	}
+	switch l {
+	case 0:
+		return "NONE"
+	case LevelDefault:
+		return "ALL"
+	}
+	result := ""
+	for bit := LevelFatal; bit != 0; bit >>= 1 {
+		if l&bit != 0 {
+			if result != "" {
+				result += "|"
 			}
+			result += bit.name()
 		}
-		GlobalFileHandler = file
 	}
+	return result
+}
+
+// LevelAtLeast 返回level及比其更严重的全部等级组成的掩码
+//
+// e.g.
+//
+//	logger.LevelAtLeast(logger.LevelWarning) // LevelWarning | LevelError | LevelNotice | LevelFatal
+func LevelAtLeast(level LogLevel) LogLevel {
+	return ^(level - 1)
+}
+
+func (l LogLevel) name() string {
+	switch l {
+	case LevelFatal:
+		return "FATAL"
+	case LevelNotice:
+		return "NOTICE"
+	case LevelError:
+		return "ERROR"
+	case LevelWarning:
+		return "WARNING"
+	case LevelSystem:
+		return "SYSTEM"
+	case LevelCommon:
+		return "COMMON"
+	case LevelHelp:
+		return "HELP"
+	case LevelDebug:
+		return "DEBUG"
+	}
+	return ""
 }
 
 // LogLevel 日志等级
@@ -247,23 +680,51 @@ type CurInfo struct {
 
 // LoggInfo 记录日志信息
 type LoggInfo struct {
-	Ts     float64              `json:"ts"`
+	Ts float64 `json:"ts"`
+	// 调用位置，仅在日志被记录到历史或Logger挂载了Printer时填充，其余情况可能为nil，需要时请使用Caller()
 	Cur    *CurInfo             `json:"-"`
 	Level  LogLevel             `json:"level"`
 	MemCur string               `json:"-"`
 	Info   *logcolor.LogTextCtx `json:"info"`
+	Fields []Field              `json:"fields,omitempty"`
+	// W3C Trace Context格式（小写十六进制）的trace ID与span ID，由WithContext通过SetTraceExtractor设置的提取器填充
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+	from    *Logger
+	pc      uintptr
 }
 
 // Logger 日志类结构体
+//
+// Logger的所有公共方法均可在多个goroutine中并发调用：历史记录、Printer列表与日志等级由内部读写锁保护，
+// Printer在锁外执行，因此Printer内部可以再次调用Logger的方法（包括AddPrinter/RemovePrinter）。
+// DefaultIO与Name应在Logger开始被并发使用之前设置完毕。
+//
+// 通过With/Named派生的Logger与其父Logger共享loggerCore，即共享历史记录、Printer、日志等级、异步队列与Formatter。
 type Logger struct {
-	Name        string
-	logs        []*LoggInfo
+	resolved uint64 // 缓存的生效等级，levelGen<<8|level，须位于首位以保证32位平台上的原子操作对齐
+	level    uint32 // 显式设置的等级，levelExplicit|level
+	*loggerCore
+	Name       string
+	logShowCur bool
+	DefaultIO  LogPrinter
+	fields     []Field
+	parent     *Logger
+	// 创建时赋给DefaultIO的internalPrinter，用于派生时判断DefaultIO是否被替换
+	internalIO LogPrinter
+}
+
+// loggerCore 同一Logger及其派生Logger之间共享的状态，由mu保护
+type loggerCore struct {
+	mu          sync.RWMutex
+	logs        *logRing
 	printer     *list.List
 	keepPrinter bool
-	logLevel    LogLevel
-	logShowCur  bool
-	DefaultIO   LogPrinter
 	latestTs    float64
+	async       *asyncQueue
+	formatter   Formatter
+	// 为1时同时记录未启用等级的日志（Retention.KeepDisabled），原子访问
+	keepDisabled uint32
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -291,52 +752,145 @@ func GetCurInfo(backLevel int) *CurInfo {
 	return emptyCurInfo
 }
 
+// callerPC 返回调用栈中向上第skip层（0为callerPC的调用者）的程序计数器，仅在需要时再通过pcCurInfo解析
+func callerPC(skip int) uintptr {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// curInfoCache 按程序计数器缓存解析后的CurInfo，调用位置的数量受代码规模限制
+var (
+	curInfoMu    sync.RWMutex
+	curInfoCache = make(map[uintptr]*CurInfo)
+)
+
+// pcCurInfo 将runtime.Callers返回的程序计数器解析为CurInfo，无法解析时返回emptyCurInfo；
+// 同一调用位置返回同一个CurInfo，调用方不应修改其内容
+func pcCurInfo(pc uintptr) *CurInfo {
+	if pc == 0 {
+		return emptyCurInfo
+	}
+	curInfoMu.RLock()
+	cur := curInfoCache[pc]
+	curInfoMu.RUnlock()
+	if cur != nil {
+		return cur
+	}
+	f, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	if f.File == "" {
+		return emptyCurInfo
+	}
+	cur = &CurInfo{
+		Function: f.Function,
+		Line:     f.Line,
+		FilePath: path.Dir(f.File),
+		FileName: path.Base(f.File),
+	}
+	curInfoMu.Lock()
+	curInfoCache[pc] = cur
+	curInfoMu.Unlock()
+	return cur
+}
+
+// GetLogger 获取指定名称的Logger，若不存在则创建并放入全局pool中，可并发调用
 func GetLogger(name string, showCur bool) *Logger {
+	poolMu.Lock()
+	defer poolMu.Unlock()
 	get := pool[name]
 
 	if get == nil {
 		current := &Logger{
-			Name:        name,
-			keepPrinter: true,
-			logs:        make([]*LoggInfo, 0),
-			printer:     list.New(),
-			logLevel:    LevelDefault,
-			logShowCur:  showCur,
-			DefaultIO:   nil,
+			loggerCore: &loggerCore{
+				keepPrinter: true,
+				logs:        newLogRing(defaultRetention),
+				printer:     list.New(),
+			},
+			Name:       name,
+			logShowCur: showCur,
+			DefaultIO:  nil,
 		}
 		current.DefaultIO = current.internalPrinter
+		current.internalIO = current.DefaultIO
+		if defaultRetention.KeepDisabled {
+			current.keepDisabled = 1
+		}
 		pool[name] = current
 		return current
 	}
 	return get
 }
 
+// expandLazyContent 展开content中延迟构建的内容，不含延迟内容时直接返回content
+func expandLazyContent(content []LogCtx) []LogCtx {
+	for i, v := range content {
+		if _, ok := v.(lazyContent); !ok {
+			continue
+		}
+		expanded := append(make([]LogCtx, 0, len(content)), content[:i]...)
+		for _, v := range content[i:] {
+			if f, ok := v.(lazyContent); ok {
+				expanded = append(expanded, f()...)
+			} else {
+				expanded = append(expanded, v)
+			}
+		}
+		return expanded
+	}
+	return content
+}
+
 func fillContent(sep string, end string, content ...LogCtx) *logcolor.LogTextCtx {
-	s := logcolor.New()
-	b := make([]byte, 0)
+	content = expandLazyContent(content)
+	buf := getBuffer()
+	defer putBuffer(buf)
+	b := *buf
+	// 仅在内容中含有LogTextCtx时才需要嵌套结构
+	var s *logcolor.LogTextCtx
 	ttl := len(content) - 1
 	for i, v := range content {
-		switch v.(type) {
-		case int, int64, int32, int16, int8, uint, uint64, uint32, uint16, uint8:
-			b = append(b, fmt.Sprintf("%d", v)...)
-		case float32, float64:
-			b = append(b, fmt.Sprintf("%.3f", v)...)
+		switch val := v.(type) {
+		case int:
+			b = strconv.AppendInt(b, int64(val), 10)
+		case int64:
+			b = strconv.AppendInt(b, val, 10)
+		case int32:
+			b = strconv.AppendInt(b, int64(val), 10)
+		case int16:
+			b = strconv.AppendInt(b, int64(val), 10)
+		case int8:
+			b = strconv.AppendInt(b, int64(val), 10)
+		case uint:
+			b = strconv.AppendUint(b, uint64(val), 10)
+		case uint64:
+			b = strconv.AppendUint(b, val, 10)
+		case uint32:
+			b = strconv.AppendUint(b, uint64(val), 10)
+		case uint16:
+			b = strconv.AppendUint(b, uint64(val), 10)
+		case uint8:
+			b = strconv.AppendUint(b, uint64(val), 10)
+		case float32:
+			b = strconv.AppendFloat(b, float64(val), 'f', 3, 32)
+		case float64:
+			b = strconv.AppendFloat(b, val, 'f', 3, 64)
 		case string:
-			b = append(b, v.(string)...)
+			b = append(b, val...)
 		case []byte:
-			b = append(b, v.([]byte)...)
+			b = append(b, val...)
 		case bool:
-			if v.(bool) {
-				b = append(b, 't', 'r', 'u', 'e')
-			} else {
-				b = append(b, 'f', 'a', 'l', 's', 'e')
-			}
+			b = strconv.AppendBool(b, val)
 		case *logcolor.LogTextCtx:
+			if s == nil {
+				s = logcolor.New()
+			}
 			if len(b) != 0 {
 				s.Then(logcolor.New().WithText(string(b)))
 				b = b[:0]
 			}
-			s.Then(v.(*logcolor.LogTextCtx))
+			s.Then(val)
 			continue
 		default:
 			b = append(b, fmt.Sprintf("%+v", v)...)
@@ -346,115 +900,167 @@ func fillContent(sep string, end string, content ...LogCtx) *logcolor.LogTextCtx
 		}
 	}
 	b = append(b, end...)
+	*buf = b
+	if s == nil {
+		return logcolor.New().WithText(string(b))
+	}
 	s.Then(logcolor.New().WithText(string(b)))
 	return s
 }
 
-func parseOption(opts ...LogComponent) logOptions {
-	dopts := defaultOptions()
-	for _, opt := range opts {
-		opt.apply(&dopts)
-	}
-	return dopts
-}
-
 ////////////////////////////////////////////////////////////////////////////////
 // CurInfo Functions
 
 func (c *CurInfo) Format() string {
-	return "(\"" + c.FileName + "\",in " + c.Function + " line " + strconv.Itoa(c.Line) + ")"
+	return string(c.appendTo(make([]byte, 0, 64)))
+}
+
+// appendTo 将Format的结果追加到b
+func (c *CurInfo) appendTo(b []byte) []byte {
+	b = append(b, "(\""...)
+	b = append(b, c.FileName...)
+	b = append(b, "\",in "...)
+	b = append(b, c.Function...)
+	b = append(b, " line "...)
+	b = strconv.AppendInt(b, int64(c.Line), 10)
+	return append(b, ')')
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // LoggInfo Functions
 
+// Caller 返回日志的调用位置，Cur未填充时按记录的程序计数器解析（不会修改Cur），没有调用位置时返回nil
+func (i *LoggInfo) Caller() *CurInfo {
+	if i.Cur != nil || i.pc == 0 {
+		return i.Cur
+	}
+	return pcCurInfo(i.pc)
+}
+
+// callerOrEmpty 与Caller相同，没有调用位置时返回emptyCurInfo
+func (i *LoggInfo) callerOrEmpty() *CurInfo {
+	if cur := i.Caller(); cur != nil {
+		return cur
+	}
+	return emptyCurInfo
+}
+
 func (i *LoggInfo) Gt(other LoggInfo) bool {
 	return i.Ts > other.Ts
 }
 
+// Time 将日志时间戳转换为time.Time
+func (i *LoggInfo) Time() time.Time {
+	return time.Unix(int64(i.Ts), int64(i.Ts*1000)%1000*1000000)
+}
+
 ////////////////////////////////////////////////////////////////////////////////
 // Logger Functions
 
 // ClearLogInfo 清空当前Logger的日志信息
 func (l *Logger) ClearLogInfo() *Logger {
-	l.logs = make([]*LoggInfo, 0)
+	l.mu.Lock()
+	l.logs.clear()
+	l.mu.Unlock()
+	return l
+}
+
+// SetRetention 设置当前Logger历史记录的保留策略，超出策略的旧记录会被立即淘汰
+func (l *Logger) SetRetention(r Retention) *Logger {
+	l.mu.Lock()
+	evicted := l.logs.setPolicy(r, float64(time.Now().UnixMilli())/1000)
+	keep := uint32(0)
+	if r.KeepDisabled {
+		keep = 1
+	}
+	atomic.StoreUint32(&l.keepDisabled, keep)
+	l.mu.Unlock()
+	notifyEvict(r.OnEvict, evicted)
 	return l
 }
 
-// SetLogLevel 设置日志等级
+// GetRetention 获取当前Logger历史记录的保留策略
+func (l *Logger) GetRetention() Retention {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.logs.policy
+}
+
+// SetLogLevel 为当前Logger显式设置日志等级，显式设置的等级优先于SetLevelFor注册的等级规则
 func (l *Logger) SetLogLevel(level LogLevel) *Logger {
-	l.logLevel = level
+	atomic.StoreUint32(&l.level, levelExplicit|uint32(level))
+	atomic.AddUint64(&levelGen, 1)
+	return l
+}
+
+// ResetLogLevel 清除当前Logger显式设置的日志等级，恢复按名称从等级规则或父Logger继承
+func (l *Logger) ResetLogLevel() *Logger {
+	atomic.StoreUint32(&l.level, 0)
+	atomic.AddUint64(&levelGen, 1)
 	return l
 }
 
+// GetLogLevel 获取当前Logger实际生效的日志等级
+func (l *Logger) GetLogLevel() LogLevel {
+	return l.effectiveLevel()
+}
+
 func (l *Logger) SetDebug(flag bool) *Logger {
 	if flag {
-		l.logLevel |= LevelDebug
-	} else {
-		l.logLevel &= ^LevelDebug
+		return l.SetLogLevel(l.effectiveLevel() | LevelDebug)
 	}
-	return l
+	return l.SetLogLevel(l.effectiveLevel() &^ LevelDebug)
 }
 
 func (l *Logger) internalPrinter(dump *LoggInfo) {
-	ent := logcolor.New()
-	//prefix := make([]byte, 0, 16+len(l.Name)+30+len(dump.MemCur))
-	ent.Then(
-		logcolor.New().WithText(
-			"[" + time.Unix(int64(dump.Ts), int64(dump.Ts*1000)%1000*1000000).Format("15:04:05.000") + "]",
-		).WithColor(TimeColor),
-	)
-	if len(dump.MemCur) > 0 {
-		ent.Then(
-			logcolor.New().WithText(
-				"[" + dump.MemCur + "]",
-			).WithColor(MemCurColor),
-		)
-	}
-	if l.logShowCur || (dump.Level&LevelShowcur) > 0 {
-		ent.Then(
-			logcolor.New().WithText(
-				dump.Cur.Format(),
-			).WithColor(StackColor),
-		)
-	}
-
-	ent.Then(
-		logcolor.New().WithText(
-			"<" + l.Name + ">",
-		),
-	)
-
-	ent.Then(logcolor.New().WithText("["))
-	ent.Then(LogPrefix[dump.Level])
-	ent.Then(logcolor.New().WithText("]"))
-
-	ent.Then(dump.Info)
+	out := console()
+	colored := out.Colored()
+	buf := getBuffer()
+	defer putBuffer(buf)
 
-	colorableStdout.Println(ent)
+	f := l.GetFormatter()
+	af, fast := f.(AppendFormatter)
+	var ent *logcolor.LogTextCtx
+	if fast {
+		*buf = af.AppendFormat(*buf, l, dump, colored)
+	} else {
+		ent = f.Format(l, dump)
+		*buf = appendLogText(*buf, ent, colored)
+	}
+	out.WriteLine(*buf)
 
-	if EnableGlobLog && GlobalFileHandler != nil && (dump.Level&GlobLogFilter != 0) {
-		info := ent.GetRawBytes()
-		info = append(info, '\n')
-		if _, e := GlobalFileHandler.WriteString(*(*string)(unsafe.Pointer(&info))); e != nil {
-			_ = GlobalFileHandler.Close()
-			GlobalFileHandler = nil
-			RootLogger.Error(WithContent("GlobalFileHandler write error:", e.Error()))
-		}
+	if !globWanted(dump.Level) {
+		return
+	}
+	switch ff := GetFileFormatter(); {
+	case ff != nil:
+		*buf = appendFormat((*buf)[:0], ff, l, dump, false)
+	case colored && fast:
+		*buf = af.AppendFormat((*buf)[:0], l, dump, false)
+	case colored:
+		*buf = ent.AppendRawBytes((*buf)[:0])
+	}
+	*buf = append(*buf, '\n')
+	if e := writeGlob(dump.Level, *buf); e != nil {
+		RootLogger.Error(WithContent("GlobalFileHandler write error:", e.Error()))
 	}
 }
 
 // AddPrinter 向当前Logger的Printer列表中添加一个Printer
 func (l *Logger) AddPrinter(printer LogPrinter) *Logger {
 	if printer != nil {
+		l.mu.Lock()
 		l.printer.PushBack(printer)
+		l.mu.Unlock()
 	}
 	return l
 }
 
 // ClearPrinter 清空当前Printer
 func (l *Logger) ClearPrinter() *Logger {
+	l.mu.Lock()
 	l.printer.Init()
+	l.mu.Unlock()
 	return l
 }
 
@@ -462,6 +1068,8 @@ func (l *Logger) ClearPrinter() *Logger {
 func (l *Logger) RemovePrinter(printer LogPrinter) *Logger {
 	if printer != nil {
 		gPtr := reflect2.PtrOf(printer)
+		l.mu.Lock()
+		defer l.mu.Unlock()
 		for e := l.printer.Front(); e != nil; e = e.Next() {
 			if reflect2.PtrOf(e.Value.(LogPrinter)) == gPtr {
 				l.printer.Remove(e)
@@ -471,6 +1079,69 @@ func (l *Logger) RemovePrinter(printer LogPrinter) *Logger {
 	}
 	return l
 }
+
+// globalPrinters 对全部Logger生效的Printer，保存[]LogPrinter，修改时整体替换，由globalPrinterMu串行化
+var (
+	globalPrinterMu sync.Mutex
+	globalPrinters  atomic.Value
+)
+
+// AddGlobalPrinter 添加一个对全部Logger（包括之后由GetLogger创建的Logger及派生Logger）生效的Printer，
+// 在各Logger自身的Printer之后、DefaultIO之前执行
+//
+// e.g.
+//
+//	logger.AddGlobalPrinter(func(info *logger.LoggInfo) {
+//		if info.Level == logger.LevelFatal {
+//			alert(info.Info.GetRawString())
+//		}
+//	})
+func AddGlobalPrinter(printer LogPrinter) {
+	if printer == nil {
+		return
+	}
+	globalPrinterMu.Lock()
+	old := loadGlobalPrinters()
+	printers := make([]LogPrinter, 0, len(old)+1)
+	globalPrinters.Store(append(append(printers, old...), printer))
+	globalPrinterMu.Unlock()
+}
+
+// RemoveGlobalPrinter 移除AddGlobalPrinter添加的Printer
+func RemoveGlobalPrinter(printer LogPrinter) {
+	if printer == nil {
+		return
+	}
+	gPtr := reflect2.PtrOf(printer)
+	globalPrinterMu.Lock()
+	defer globalPrinterMu.Unlock()
+	old := loadGlobalPrinters()
+	for i, p := range old {
+		if reflect2.PtrOf(p) == gPtr {
+			printers := make([]LogPrinter, 0, len(old)-1)
+			globalPrinters.Store(append(append(printers, old[:i]...), old[i+1:]...))
+			return
+		}
+	}
+}
+
+func loadGlobalPrinters() []LogPrinter {
+	printers, _ := globalPrinters.Load().([]LogPrinter)
+	return printers
+}
+
+// globalPrinterProc 执行一个全局Printer，发生panic时将其移除
+func globalPrinterProc(dump *LoggInfo, printer LogPrinter) {
+	defer func() {
+		if r := recover(); r != nil {
+			// 先移除再输出错误，避免输出错误时再次调用该Printer
+			RemoveGlobalPrinter(printer)
+			RootLogger.Error(WithContent("GlobalPrinter Failed To Print:", r))
+		}
+	}()
+	printer(dump)
+}
+
 func (l *Logger) GetLogs(from float64, levelMask LogLevel, maxCnt int) []*LoggInfo {
 
 	predicate := func(x interface{}) bool {
@@ -478,7 +1149,14 @@ func (l *Logger) GetLogs(from float64, levelMask LogLevel, maxCnt int) []*LoggIn
 		return ok && log.Ts > from && (levelMask&log.Level) != 0
 	}
 
-	q := linq.From(l.logs).Where(predicate)
+	l.mu.Lock()
+	evicted := l.logs.trim(float64(time.Now().UnixMilli())/1000, nil)
+	logs := l.logs.snapshot()
+	onEvict := l.logs.policy.OnEvict
+	l.mu.Unlock()
+	notifyEvict(onEvict, evicted)
+
+	q := linq.From(logs).Where(predicate)
 	skipCnt := 0
 	if maxCnt > 0 {
 		skipCnt = q.Count() - maxCnt
@@ -499,10 +1177,9 @@ func (l *Logger) GetLogs(from float64, levelMask LogLevel, maxCnt int) []*LoggIn
 
 // GetLatestLog 从当前Logger中获取最后一条记录的信息，如果没有记录，则返回nil
 func (l *Logger) GetLatestLog() *LoggInfo {
-	if len(l.logs) == 0 {
-		return nil
-	}
-	return l.logs[len(l.logs)-1]
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.logs.latest()
 }
 
 func (l *Logger) printerProc(dump *LoggInfo, printer *list.Element) {
@@ -512,26 +1189,38 @@ func (l *Logger) printerProc(dump *LoggInfo, printer *list.Element) {
 	defer func(l *Logger) {
 		if r := recover(); r != nil {
 			println("Printer Failed To Print:%v", r)
+			l.mu.Lock()
 			l.printer.Remove(printer)
+			l.mu.Unlock()
 		}
 	}(l)
 	(printer.Value.(LogPrinter))(dump)
 }
 
+// print 依次调用Printer与DefaultIO，Printer列表在锁内拷贝后于锁外执行
 func (l *Logger) print(dump *LoggInfo) {
-	if l.printer != nil {
-		if l.printer.Len() > 0 {
-			for e := l.printer.Front(); e != nil; e = e.Next() {
-				l.printerProc(dump, e)
-			}
-		}
+	l.mu.RLock()
+	printers := make([]*list.Element, 0, l.printer.Len())
+	for e := l.printer.Front(); e != nil; e = e.Next() {
+		printers = append(printers, e)
+	}
+	keepPrinter := l.keepPrinter
+	l.mu.RUnlock()
+
+	for _, e := range printers {
+		l.printerProc(dump, e)
+	}
+	for _, p := range loadGlobalPrinters() {
+		globalPrinterProc(dump, p)
 	}
-	if l.keepPrinter {
+	if keepPrinter {
 		if l.DefaultIO != nil {
 			defer func(l *Logger) {
 				if r := recover(); r != nil {
 					println("DefaultIO Failed To Print:%v", r)
+					l.mu.Lock()
 					l.keepPrinter = false
+					l.mu.Unlock()
 				}
 			}(l)
 			l.DefaultIO(dump)
@@ -539,65 +1228,141 @@ func (l *Logger) print(dump *LoggInfo) {
 	}
 }
 
-func (l *Logger) _log(info *logcolor.LogTextCtx, level LogLevel, backLevel int, log bool, log2logs bool, cur string) *LoggInfo {
+// Enabled 判断level等级的日志是否会被当前Logger输出，可用于在构建开销较大的日志内容前提前判断
+func (l *Logger) Enabled(level LogLevel) bool {
+	return level&l.effectiveLevel() != 0
+}
+
+// wants 判断level等级的日志是否需要构建：等级已启用，或保留策略要求记录未启用等级的日志
+func (l *Logger) wants(level LogLevel) bool {
+	return l.Enabled(level) || atomic.LoadUint32(&l.keepDisabled) != 0
+}
+
+// hasPrinters 判断当前Logger是否挂载了Printer（包括全局Printer）或使用自定义的DefaultIO，此时需要在输出前解析调用位置
+func (l *Logger) hasPrinters() bool {
+	if len(loadGlobalPrinters()) > 0 || l.customIO() {
+		return true
+	}
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.printer.Len() > 0
+}
+
+// customIO 判断DefaultIO是否已被替换为创建时的internalPrinter以外的函数
+func (l *Logger) customIO() bool {
+	return l.DefaultIO != nil && (l.internalIO == nil || reflect2.PtrOf(l.DefaultIO) != reflect2.PtrOf(l.internalIO))
+}
+
+func (l *Logger) _log(info *logcolor.LogTextCtx, level LogLevel, backLevel int, log bool, log2logs bool, cur string, fields []Field, traceID string, spanID string) *LoggInfo {
 	dump := &LoggInfo{
-		Ts:     float64(time.Now().UnixMilli()) / 1000,
-		Cur:    GetCurInfo(backLevel + 2),
-		Level:  level,
-		Info:   info,
-		MemCur: cur,
+		Ts:      float64(time.Now().UnixMilli()) / 1000,
+		Level:   level,
+		Info:    info,
+		MemCur:  cur,
+		Fields:  l.withOwnFields(fields),
+		TraceID: traceID,
+		SpanID:  spanID,
+		from:    l,
+	}
+	if backLevel >= 0 {
+		dump.pc = callerPC(backLevel + 2)
+	}
+	if dump.pc == 0 {
+		dump.Cur = emptyCurInfo
+	}
+	l.emit(dump, log, log2logs)
+	return dump
+}
+
+// emit 将已构建的日志记录到历史并按等级输出；未启用的等级仅在Retention.KeepDisabled时记录，
+// 调用位置仅在日志被记录到历史或Logger挂载了Printer时解析
+func (l *Logger) emit(dump *LoggInfo, log bool, log2logs bool) {
+	enabled := l.Enabled(dump.Level)
+	log2logs = log2logs && (enabled || atomic.LoadUint32(&l.keepDisabled) != 0)
+	if dump.Cur == nil && dump.pc != 0 && (log2logs || (enabled && log && l.hasPrinters())) {
+		dump.Cur = pcCurInfo(dump.pc)
 	}
+	var evicted []*LoggInfo
+	l.mu.Lock()
 	if log2logs {
-		l.logs = append(l.logs, dump)
+		evicted = l.logs.push(dump)
 	}
+	onEvict := l.logs.policy.OnEvict
 	l.latestTs = dump.Ts
-	if level&l.logLevel > 0 && log {
-		l.print(dump)
+	async := l.async
+	l.mu.Unlock()
+	notifyEvict(onEvict, evicted)
+	if enabled && log {
+		if async == nil || !async.put(dump) {
+			l.print(dump)
+		} else if dump.Level == LevelFatal {
+			// Fatal日志通常意味着程序即将退出，需等待其输出完成
+			async.flush()
+		}
 	}
-	return dump
 }
+
+// logAt 以level等级输出日志，level未启用且无需记录时直接返回，不解析日志组件、不构建内容也不获取调用位置
+func (l *Logger) logAt(level LogLevel, opts []LogComponent) {
+	if !l.wants(level) {
+		return
+	}
+	dopts := parseOption(opts...)
+	dopts.resolveContexts()
+	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), level, dopts.BacktraceLevelDelta+1, dopts.Log, dopts.Log2Logs, dopts.Cur, dopts.Fields, dopts.TraceID, dopts.SpanID)
+	releaseOption(dopts)
+}
+
 func (l *Logger) Log(opts ...LogComponent) {
 	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), dopts.Level, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	if l.wants(dopts.Level) {
+		dopts.resolveContexts()
+		l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), dopts.Level, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur, dopts.Fields, dopts.TraceID, dopts.SpanID)
+	}
+	releaseOption(dopts)
 }
 func (l *Logger) Common(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelCommon, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelCommon, opts)
 }
 
 func (l *Logger) Error(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelError, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelError, opts)
 }
 
 func (l *Logger) Debug(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelDebug, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelDebug, opts)
 }
 
 func (l *Logger) Help(opts ...LogComponent) {
+	if !l.Enabled(LevelHelp) {
+		return
+	}
 	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelHelp, dopts.BacktraceLevelDelta, dopts.Log, false, dopts.Cur)
+	dopts.resolveContexts()
+	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelHelp, dopts.BacktraceLevelDelta, dopts.Log, false, dopts.Cur, dopts.Fields, dopts.TraceID, dopts.SpanID)
+	releaseOption(dopts)
 }
 
 func (l *Logger) System(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelSystem, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelSystem, opts)
 }
 
 func (l *Logger) Notice(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelNotice, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelNotice, opts)
 }
 
 func (l *Logger) Warning(opts ...LogComponent) {
-	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelWarning, dopts.BacktraceLevelDelta, dopts.Log, dopts.Log2Logs, dopts.Cur)
+	l.logAt(LevelWarning, opts)
 }
 
 func (l *Logger) Fatal(opts ...LogComponent) {
+	if !l.wants(LevelFatal) {
+		return
+	}
 	dopts := parseOption(opts...)
-	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelFatal, dopts.BacktraceLevelDelta, true, true, dopts.Cur)
+	dopts.resolveContexts()
+	l._log(fillContent(dopts.Sep, dopts.End, dopts.Info...), LevelFatal, dopts.BacktraceLevelDelta, true, true, dopts.Cur, dopts.Fields, dopts.TraceID, dopts.SpanID)
+	releaseOption(dopts)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -0,0 +1,333 @@
+package logger
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	SetConsoleOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+// useGlobLog 在临时目录中打开全局日志文件（不重定向标准错误，但切片后标准错误会跟随新文件），测试结束时关闭并恢复标准错误
+func useGlobLog(t *testing.T, config Config) string {
+	t.Helper()
+	if saved, err := dupStderr(); err == nil {
+		t.Cleanup(func() { _ = restoreStderr(saved) })
+	}
+	dir := t.TempDir()
+	config.Name = filepath.Join(dir, "glob.log")
+	config.ArchiveDir = filepath.Join(dir, "logs")
+	globMu.Lock()
+	current := mergeConfig(config)
+	err := openGlob(&current, nil)
+	globMu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(resetGlobLog)
+	return dir
+}
+
+// resetGlobLog 关闭全局日志文件并恢复为未初始化状态
+func resetGlobLog() {
+	_ = CloseGlobLog()
+	SetGlobLogFilter(LevelDefault)
+	archiveMu.Lock() // 等待后台归档维护结束
+	archiveMu.Unlock()
+}
+
+// countLines 统计dir下（含子目录）全部日志文件中包含marker的行数
+func countLines(t *testing.T, dir, marker string) int {
+	t.Helper()
+	n := 0
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || !strings.HasSuffix(p, ".log") {
+			return err
+		}
+		f, err := os.Open(p)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			if strings.Contains(s.Text(), marker) {
+				n++
+			}
+		}
+		return s.Err()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestConcurrentLogging(t *testing.T) {
+	const workers, perWorker = 8, 200
+	l := GetLogger(t.Name(), false)
+	l.SetRetention(Retention{MaxEntries: 100})
+	var printed int64
+	printer := func(info *LoggInfo) { atomic.AddInt64(&printed, 1) }
+	l.AddPrinter(printer)
+	defer l.RemovePrinter(printer)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				switch i % 4 {
+				case 0:
+					l.Common(WithContent("common", w, i))
+				case 1:
+					l.Error(WithContent("error", w, i), WithKVs("w", w))
+				case 2:
+					l.With(Int("w", w)).Warning(WithContent("warning", i))
+				default:
+					l.Log(WithLevel(LevelSystem), WithContent("system", i))
+				}
+				_ = l.GetLogs(0, LevelDefault, 10)
+				_ = l.GetLatestLog()
+			}
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < perWorker; i++ {
+			l.SetDebug(i%2 == 0)
+			l.SetRetention(Retention{MaxEntries: 50 + i%100})
+		}
+	}()
+	wg.Wait()
+
+	if got := atomic.LoadInt64(&printed); got != workers*perWorker {
+		t.Fatalf("printer called %d times, want %d", got, workers*perWorker)
+	}
+	if n := len(l.GetLogs(0, LevelDefault, -1)); n == 0 || n > 150 {
+		t.Fatalf("history holds %d entries, want 1..150", n)
+	}
+}
+
+func TestConcurrentPrinters(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	var calls int64
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				p := func(info *LoggInfo) { atomic.AddInt64(&calls, 1) }
+				l.AddPrinter(p)
+				l.Common(WithContent("with printer", i))
+				l.RemovePrinter(p)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				l.Common(WithContent("plain", i))
+			}
+		}()
+	}
+	// Printer内部再次调用Logger的方法不应死锁
+	var reentrant LogPrinter
+	reentrant = func(info *LoggInfo) {
+		l.RemovePrinter(reentrant)
+		l.Help(WithContent("from printer"))
+	}
+	l.AddPrinter(reentrant)
+	wg.Wait()
+	if atomic.LoadInt64(&calls) < 4*200 {
+		t.Fatalf("printers called %d times, want at least %d", calls, 4*200)
+	}
+	l.ClearPrinter()
+}
+
+func TestConcurrentGetLogger(t *testing.T) {
+	const workers = 16
+	got := make([]*Logger, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			got[w] = GetLogger(t.Name(), false)
+			for i := 0; i < 50; i++ {
+				GetLogger(t.Name()+"."+strconv.Itoa(i%5), false).Common(WithContent(w, i))
+			}
+		}(w)
+	}
+	wg.Wait()
+	for _, l := range got {
+		if l != got[0] {
+			t.Fatal("GetLogger returned different loggers for the same name")
+		}
+	}
+}
+
+func TestConcurrentGlobLogFilter(t *testing.T) {
+	dir := useGlobLog(t, Config{Durability: DurabilityWrite})
+	l := GetLogger(t.Name(), false)
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				l.Error(WithContent("filtered-marker", w, i))
+			}
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 200; i++ {
+			if i%2 == 0 {
+				SetGlobLogFilter(LevelError)
+			} else {
+				SetGlobLogFilter(LevelCommon)
+			}
+		}
+	}()
+	wg.Wait()
+
+	SetGlobLogFilter(LevelCommon)
+	l.Error(WithContent("excluded-marker"))
+	l.Common(WithContent("included-marker"))
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, dir, "excluded-marker"); n != 0 {
+		t.Fatalf("filtered level written %d times", n)
+	}
+	if n := countLines(t, dir, "included-marker"); n != 1 {
+		t.Fatalf("included level written %d times, want 1", n)
+	}
+}
+
+func TestConcurrentRotation(t *testing.T) {
+	const workers, perWorker = 8, 300
+	dir := useGlobLog(t, Config{Durability: DurabilityWrite, MaxSize: 4 << 10})
+	l := GetLogger(t.Name(), false)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				l.Common(WithContent("rotation-marker", w, i))
+			}
+		}(w)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			globMu.Lock()
+			rotateGlob()
+			globMu.Unlock()
+		}
+	}()
+	wg.Wait()
+	if err := Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := countLines(t, dir, "rotation-marker"); n != workers*perWorker {
+		t.Fatalf("found %d lines across rotated files, want %d", n, workers*perWorker)
+	}
+	archives, _ := os.ReadDir(filepath.Join(dir, "logs"))
+	if len(archives) < 2 {
+		t.Fatalf("expected several archives, got %d", len(archives))
+	}
+}
+
+func TestInitGlobLogReplacesActiveCapture(t *testing.T) {
+	if saved, err := dupStderr(); err == nil {
+		defer func() { _ = restoreStderr(saved) }()
+	}
+	l := GetLogger(t.Name(), false)
+	// 放慢读取管道的goroutine
+	slow := func(info *LoggInfo) { time.Sleep(time.Millisecond) }
+	l.AddPrinter(slow)
+	defer l.RemovePrinter(slow)
+	stop, err := CaptureStderr(l, LevelError)
+	if err == errStderrUnsupported {
+		t.Skip(err)
+	} else if err != nil {
+		t.Fatal(err)
+	}
+	defer stop()
+	defer resetGlobLog()
+
+	// 初始化时上一次接管仍有未读取的行
+	line := strings.Repeat("x", 99) + "\n"
+	for i := 0; i < 200; i++ {
+		_, _ = os.Stderr.WriteString(line)
+	}
+	dir := t.TempDir()
+	within(t, 3*time.Second, func() {
+		err = InitGlobLogWithConfigE(Config{Name: filepath.Join(dir, "glob.log"), ArchiveDir: filepath.Join(dir, "logs"), CaptureStderr: true, StderrLogger: l})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPanickingGlobalPrinterIsReported(t *testing.T) {
+	var reported []string
+	var mu sync.Mutex
+	collect := func(info *LoggInfo) {
+		if info.Level == LevelError {
+			mu.Lock()
+			reported = append(reported, info.Info.GetRawString())
+			mu.Unlock()
+		}
+	}
+	RootLogger.AddPrinter(collect)
+	defer RootLogger.RemovePrinter(collect)
+	var calls int64
+	AddGlobalPrinter(func(info *LoggInfo) {
+		atomic.AddInt64(&calls, 1)
+		panic("broken printer")
+	})
+
+	l := GetLogger(t.Name(), false)
+	l.Common(WithContent("first"))
+	l.Common(WithContent("second"))
+	if got := atomic.LoadInt64(&calls); got != 1 {
+		t.Fatalf("panicking printer called %d times, want 1", got)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != 1 || !strings.Contains(reported[0], "broken printer") || strings.Contains(reported[0], "%v") {
+		t.Fatalf("reported %q", reported)
+	}
+}
+
+func TestCustomDefaultIOGetsCaller(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	var calls int64
+	l.DefaultIO = func(info *LoggInfo) {
+		_ = info.Cur.Format()
+		atomic.AddInt64(&calls, 1)
+	}
+	l.Help(WithContent("not kept in history"))
+	l.Common(WithContent("kept in history"))
+	if got := atomic.LoadInt64(&calls); got != 2 {
+		t.Fatalf("DefaultIO called %d times, want 2", got)
+	}
+}
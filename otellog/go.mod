@@ -0,0 +1,20 @@
+module github.com/fexli/logger/otellog
+
+go 1.21
+
+// 核心模块尚未发布包含SetTraceExtractor的版本，仓库内开发时使用本地的核心模块
+replace github.com/fexli/logger => ../
+
+require (
+	github.com/fexli/logger v0.0.0-00010101000000-000000000000
+	go.opentelemetry.io/otel/trace v1.24.0
+)
+
+require (
+	github.com/ahmetb/go-linq/v3 v3.2.0 // indirect
+	github.com/fatih/structs v1.1.0 // indirect
+	github.com/modern-go/reflect2 v1.0.2 // indirect
+	github.com/xo/terminfo v0.0.0-20210125001918-ca9a967f8778 // indirect
+	go.opentelemetry.io/otel v1.24.0 // indirect
+	golang.org/x/sys v0.0.0-20220708085239-5a0f0661e09d // indirect
+)
@@ -0,0 +1,23 @@
+// Package otellog 将OpenTelemetry的SpanContext接入logger，使日志自动带上trace_id与span_id
+package otellog
+
+import (
+	"context"
+
+	"github.com/fexli/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Extract 从ctx中取出OpenTelemetry的TraceID与SpanID，ctx中没有有效的SpanContext时返回空字符串
+func Extract(ctx context.Context) (traceID, spanID string) {
+	sc := trace.SpanContextFromContext(ctx)
+	if !sc.IsValid() {
+		return "", ""
+	}
+	return sc.TraceID().String(), sc.SpanID().String()
+}
+
+// Install 将Extract设置为logger的全局TraceExtractor
+func Install() {
+	logger.SetTraceExtractor(Extract)
+}
@@ -0,0 +1,60 @@
+package otellog_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/fexli/logger"
+	"github.com/fexli/logger/otellog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetConsoleOutput(io.Discard)
+	os.Exit(m.Run())
+}
+
+func spanContext(t *testing.T) context.Context {
+	t.Helper()
+	traceID, err := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spanID, err := trace.SpanIDFromHex("00f067aa0ba902b7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+	return trace.ContextWithSpanContext(context.Background(), sc)
+}
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name            string
+		ctx             context.Context
+		traceID, spanID string
+	}{
+		{"valid span", spanContext(t), "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"},
+		{"no span", context.Background(), "", ""},
+		{"invalid span", trace.ContextWithSpanContext(context.Background(), trace.SpanContext{}), "", ""},
+	}
+	for _, tt := range tests {
+		traceID, spanID := otellog.Extract(tt.ctx)
+		if traceID != tt.traceID || spanID != tt.spanID {
+			t.Errorf("%s: Extract = (%q, %q), want (%q, %q)", tt.name, traceID, spanID, tt.traceID, tt.spanID)
+		}
+	}
+}
+
+func TestInstall(t *testing.T) {
+	otellog.Install()
+	defer logger.SetTraceExtractor(nil)
+	l := logger.GetLogger(t.Name(), false)
+	l.Common(logger.WithContent("traced"), logger.WithContext(spanContext(t)))
+	dump := l.GetLatestLog()
+	if dump.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" || dump.SpanID != "00f067aa0ba902b7" {
+		t.Fatalf("log carries trace %q span %q", dump.TraceID, dump.SpanID)
+	}
+}
@@ -0,0 +1,7 @@
+//go:build race
+// +build race
+
+package logger
+
+// raceEnabled 竞态检测下sync.Pool会随机丢弃放回的对象，内存分配数不稳定
+const raceEnabled = true
@@ -0,0 +1,167 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"github.com/fexli/logger/logcolor"
+	"log/slog"
+)
+
+// SlogHandler 以Logger为输出目标的slog.Handler，slog的日志与本库的日志共用控制台、日志文件与Printer
+//
+// slog等级按以下规则映射：低于Info为LevelDebug，Info为LevelCommon，Warn为LevelWarning，
+// Error为LevelError，Error+4及以上为LevelFatal；属性与分组以结构化字段（分组为嵌套对象）保存，
+// 传入InfoContext等函数的context携带的字段与提取器提取的字段（见WithContext）作为顶层字段追加在最后。
+//
+// e.g.
+//
+//	slog.SetDefault(slog.New(logger.NewSlogHandler(logger.GetLogger("app", false))))
+type SlogHandler struct {
+	l      *Logger
+	frames []slogFrame
+}
+
+// slogFrame 一层分组及其已添加的属性，frames[0]为顶层
+type slogFrame struct {
+	group  string
+	fields []Field
+}
+
+// NewSlogHandler 创建输出到l的slog.Handler
+func NewSlogHandler(l *Logger) *SlogHandler {
+	return &SlogHandler{l: l, frames: []slogFrame{{}}}
+}
+
+// SlogLevel 将slog等级映射为日志等级
+func SlogLevel(level slog.Level) LogLevel {
+	switch {
+	case level < slog.LevelInfo:
+		return LevelDebug
+	case level < slog.LevelWarn:
+		return LevelCommon
+	case level < slog.LevelError:
+		return LevelWarning
+	case level < slog.LevelError+4:
+		return LevelError
+	}
+	return LevelFatal
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return SlogLevel(level)&h.l.effectiveLevel() != 0
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	frames := h.cloneFrames()
+	last := &frames[len(frames)-1]
+	r.Attrs(func(a slog.Attr) bool {
+		last.fields = appendSlogAttr(last.fields, a)
+		return true
+	})
+
+	dump := &LoggInfo{
+		Level:  SlogLevel(r.Level),
+		Info:   logcolor.New().WithText(r.Message),
+		Fields: h.l.withOwnFields(contextFields(ctx, foldSlogFrames(frames))),
+		from:   h.l,
+		pc:     r.PC,
+	}
+	if r.PC == 0 {
+		dump.Cur = emptyCurInfo
+	}
+	dump.TraceID, dump.SpanID = traceFromContext(ctx)
+	if !r.Time.IsZero() {
+		dump.Ts = float64(r.Time.UnixMilli()) / 1000
+	}
+	h.l.emit(dump, true, true)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	frames := h.cloneFrames()
+	last := &frames[len(frames)-1]
+	for _, a := range attrs {
+		last.fields = appendSlogAttr(last.fields, a)
+	}
+	return &SlogHandler{l: h.l, frames: frames}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	frames := make([]slogFrame, len(h.frames), len(h.frames)+1)
+	copy(frames, h.frames)
+	return &SlogHandler{l: h.l, frames: append(frames, slogFrame{group: name})}
+}
+
+// cloneFrames 复制分组栈，并复制最后一层的字段以便追加
+func (h *SlogHandler) cloneFrames() []slogFrame {
+	frames := make([]slogFrame, len(h.frames))
+	copy(frames, h.frames)
+	last := &frames[len(frames)-1]
+	last.fields = append(make([]Field, 0, len(last.fields)+4), last.fields...)
+	return frames
+}
+
+// foldSlogFrames 由内向外将每层分组折叠为嵌套对象，没有任何属性的分组被省略
+func foldSlogFrames(frames []slogFrame) []Field {
+	for i := len(frames) - 1; i > 0; i-- {
+		if len(frames[i].fields) == 0 {
+			continue
+		}
+		parent := append(make([]Field, 0, len(frames[i-1].fields)+1), frames[i-1].fields...)
+		frames[i-1].fields = append(parent, Object(frames[i].group, frames[i].fields...))
+	}
+	return frames[0].fields
+}
+
+// appendSlogAttr 将slog属性转换为字段追加到fields，空属性与空分组被忽略，无键分组内联到当前层
+func appendSlogAttr(fields []Field, a slog.Attr) []Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, sub := range attrs {
+				fields = appendSlogAttr(fields, sub)
+			}
+			return fields
+		}
+		sub := make([]Field, 0, len(attrs))
+		for _, s := range attrs {
+			sub = appendSlogAttr(sub, s)
+		}
+		if len(sub) == 0 {
+			return fields
+		}
+		return append(fields, Object(a.Key, sub...))
+	case slog.KindString:
+		return append(fields, String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, Int64(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, Uint64(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, Float64(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, Bool(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, Duration(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, Time(a.Key, v.Time()))
+	}
+	return append(fields, Any(a.Key, v.Any()))
+}
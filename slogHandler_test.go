@@ -0,0 +1,40 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"testing/slogtest"
+)
+
+func TestSlogHandler(t *testing.T) {
+	l := GetLogger(t.Name(), false)
+	l.DefaultIO = nil
+	var mu sync.Mutex
+	var lines [][]byte
+	l.AddPrinter(func(info *LoggInfo) {
+		mu.Lock()
+		lines = append(lines, JSONFormatter{}.AppendFormat(nil, l, info, false))
+		mu.Unlock()
+	})
+	results := func() []map[string]any {
+		mu.Lock()
+		defer mu.Unlock()
+		ms := make([]map[string]any, 0, len(lines))
+		for _, line := range lines {
+			var m map[string]any
+			if err := json.Unmarshal(line, &m); err != nil {
+				t.Fatalf("%s: %v", line, err)
+			}
+			ms = append(ms, m)
+		}
+		lines = nil
+		return ms
+	}
+	if err := slogtest.TestHandler(NewSlogHandler(l), results); err != nil {
+		t.Fatal(err)
+	}
+}